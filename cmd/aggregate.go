@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/aggregate"
+	"stackpulse/internal/display"
+)
+
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate",
+	Short: "Show a combined dashboard for several remote StackPulse instances",
+	Long: `Poll remote StackPulse instances (started with watch --api-addr) and show
+one combined dashboard with the alerts of every service.
+
+Examples:
+  stackpulse aggregate --targets targets.yaml
+  stackpulse aggregate --targets targets.yaml --interval 5s`,
+	RunE: runAggregate,
+}
+
+var (
+	targetsFile       string
+	aggregateInterval time.Duration
+	aggregateTimeout  time.Duration
+	aggregateGlyphs   string
+	aggregateUnits    string
+	aggregateColor    string
+	aggregateTheme    string
+)
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.Flags().StringVar(&targetsFile, "targets", "", "YAML file listing the instances to poll")
+	aggregateCmd.Flags().DurationVar(&aggregateInterval, "interval", 2*time.Second, "Polling interval")
+	aggregateCmd.Flags().DurationVar(&aggregateTimeout, "timeout", time.Second, "Timeout for each target request")
+	aggregateCmd.Flags().StringVar(&aggregateGlyphs, "glyphs", "emoji", "Status indicators on the dashboard: emoji, unicode or ascii")
+	aggregateCmd.Flags().StringVar(&aggregateUnits, "units", "auto", "Memory column unit: si (MB), iec (MiB) or auto (scaled binary units)")
+	aggregateCmd.Flags().StringVar(&aggregateColor, "color", "auto", "Color the dashboard: auto (terminals without NO_COLOR), always or never")
+	aggregateCmd.Flags().StringVar(&aggregateTheme, "theme", "dark", "Dashboard palette: dark or light for light terminal backgrounds")
+	aggregateCmd.MarkFlagRequired("targets")
+}
+
+func runAggregate(cmd *cobra.Command, args []string) error {
+	if aggregateInterval <= 0 || aggregateTimeout <= 0 {
+		return fmt.Errorf("interval and timeout must be greater than 0")
+	}
+	glyphSet, ok := display.LookupGlyphs(aggregateGlyphs)
+	if !ok {
+		return fmt.Errorf("unknown glyph set %q (expected emoji, unicode or ascii)", aggregateGlyphs)
+	}
+	units, ok := display.LookupUnits(aggregateUnits)
+	if !ok {
+		return fmt.Errorf("unknown units %q (expected si, iec or auto)", aggregateUnits)
+	}
+	colorMode, ok := display.LookupColorMode(aggregateColor)
+	if !ok {
+		return fmt.Errorf("unknown color mode %q (expected auto, always or never)", aggregateColor)
+	}
+	theme, ok := display.LookupTheme(aggregateTheme)
+	if !ok {
+		return fmt.Errorf("unknown theme %q (expected dark or light)", aggregateTheme)
+	}
+	display.SetColorMode(colorMode)
+
+	targets, err := aggregate.LoadTargets(targetsFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nShutting down gracefully...")
+		cancel()
+	}()
+
+	aggregator := aggregate.New(targets, aggregateTimeout)
+	dashboard := display.NewFleetDashboard()
+	dashboard.SetGlyphs(glyphSet)
+	dashboard.SetUnits(units)
+	dashboard.SetTheme(theme)
+
+	ticker := time.NewTicker(aggregateInterval)
+	defer ticker.Stop()
+
+	for {
+		states := aggregator.Poll(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		entries := make([]display.FleetEntry, len(states))
+		for i, state := range states {
+			entries[i] = display.FleetEntry{
+				Name:     state.Target.Name,
+				Status:   state.Status,
+				Err:      state.Err,
+				LastSeen: state.LastSeen,
+			}
+		}
+		dashboard.Update(entries, aggregate.Alerts(states))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"stackpulse/internal/store"
+)
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Show the alert history recorded by watch --alert-db",
+	Long: `Print the alerts raised and resolved in an alert database written by
+watch --alert-db, oldest first.
+
+Examples:
+  stackpulse watch --port 3000 --alert-db alerts.sqlite
+  stackpulse alerts --db alerts.sqlite
+  stackpulse alerts --db alerts.sqlite --since 1h`,
+	RunE: runAlerts,
+}
+
+var (
+	alertsDB    string
+	alertsSince time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(alertsCmd)
+
+	alertsCmd.Flags().StringVar(&alertsDB, "db", "", "Alert database written by watch --alert-db")
+	alertsCmd.Flags().DurationVar(&alertsSince, "since", 24*time.Hour, "Show the transitions of this long ago and later")
+	alertsCmd.MarkFlagRequired("db")
+}
+
+func runAlerts(cmd *cobra.Command, args []string) error {
+	if alertsSince <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	// A missing database is not a usage error
+	cmd.SilenceUsage = true
+
+	// Opening would create an empty database at a mistyped path
+	if _, err := os.Stat(alertsDB); err != nil {
+		return fmt.Errorf("failed to open alert database: %w", err)
+	}
+
+	db, err := store.OpenAlertDB(alertsDB)
+	if err != nil {
+		return fmt.Errorf("failed to open alert database: %w", err)
+	}
+	defer db.Close()
+
+	transitions, err := db.Since(time.Now().Add(-alertsSince))
+	if err != nil {
+		return err
+	}
+	printAlertHistory(os.Stdout, transitions)
+	return nil
+}
+
+// printAlertHistory writes transitions as a table, one row each.
+func printAlertHistory(out io.Writer, transitions []store.AlertTransition) {
+	if len(transitions) == 0 {
+		fmt.Fprintln(out, "No alerts recorded")
+		return
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Time", "PID", "Type", "Severity", "State", "Value", "Threshold"})
+	table.SetBorder(true)
+	for _, t := range transitions {
+		state := "raised"
+		if t.Resolved {
+			state = "resolved"
+		}
+		table.Append([]string{
+			t.Timestamp.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", t.PID),
+			string(t.Type),
+			string(t.Severity),
+			state,
+			fmt.Sprintf("%.2f", t.Value),
+			fmt.Sprintf("%.2f", t.Threshold),
+		})
+	}
+	table.Render()
+}
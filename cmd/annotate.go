@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/api"
+	"stackpulse/internal/types"
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <text>",
+	Short: "Mark an event such as a deploy on a running watch",
+	Long: `Send a timestamped marker to a watch started with --socket. The marker is
+shown on the dashboard with key metrics before and after it.
+
+Examples:
+  stackpulse annotate "deploy v1.2.3"
+  stackpulse annotate --socket /tmp/stackpulse.sock "deploy v1.2.3"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAnnotate,
+}
+
+var annotateSocket string
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().StringVar(&annotateSocket, "socket", defaultSocketPath(), "Control socket of the running watch")
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) error {
+	var annotation types.Annotation
+	if err := api.SendCommand(annotateSocket, "annotate", strings.Join(args, " "), &annotation); err != nil {
+		return fmt.Errorf("failed to annotate: %w", err)
+	}
+
+	fmt.Printf("Marked %q at %s\n", annotation.Text, annotation.Timestamp.Format("15:04:05"))
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+	"stackpulse/internal/demo"
+	"stackpulse/internal/monitor"
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Run the dashboard against a synthetic misbehaving service",
+	Long: `Run the dashboard and alerting against a built-in synthetic target that
+produces periodic CPU spikes, event loop stalls, and steady memory growth,
+so you can see alerts fire without a real Node.js service.
+
+Examples:
+  stackpulse demo
+  stackpulse demo --leak-rate 5 --spike-every 10s --duration 1m`,
+	RunE: runDemo,
+}
+
+var (
+	demoDuration    time.Duration
+	demoPollingMs   int
+	demoSpikeEvery  time.Duration
+	demoSpikeLength time.Duration
+	demoLeakRate    float64
+	demoStallEvery  time.Duration
+	demoStallLength time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+
+	defaults := demo.DefaultOptions()
+	demoCmd.Flags().DurationVar(&demoDuration, "duration", 0, "Stop the demo after this long (0 runs until interrupted)")
+	demoCmd.Flags().IntVar(&demoPollingMs, "polling-ms", 500, "Polling interval in milliseconds")
+	demoCmd.Flags().DurationVar(&demoSpikeEvery, "spike-every", defaults.SpikeEvery, "Interval between CPU spikes (0 disables)")
+	demoCmd.Flags().DurationVar(&demoSpikeLength, "spike-length", defaults.SpikeLength, "Length of each CPU spike")
+	demoCmd.Flags().Float64Var(&demoLeakRate, "leak-rate", defaults.LeakRate, "Memory growth in MB per second (0 disables)")
+	demoCmd.Flags().DurationVar(&demoStallEvery, "stall-every", defaults.StallEvery, "Interval between event loop stalls (0 disables)")
+	demoCmd.Flags().DurationVar(&demoStallLength, "stall-length", defaults.StallLength, "Length of each event loop stall")
+}
+
+func runDemo(cmd *cobra.Command, args []string) error {
+	if demoPollingMs < 1 {
+		return fmt.Errorf("polling interval must be at least 1ms")
+	}
+
+	cfg := &config.ServiceConfig{
+		PID:                demo.PID,
+		HeapLimit:          "150MB",
+		CPUThreshold:       70,
+		PollingInterval:    time.Duration(demoPollingMs) * time.Millisecond,
+		GCReclaimThreshold: 0.1,
+		GCReclaimCount:     3,
+		HistorySize:        config.DefaultHistorySize,
+		AlertHistory:       10,
+	}
+
+	source := demo.NewSource(demo.Options{
+		SpikeEvery:  demoSpikeEvery,
+		SpikeLength: demoSpikeLength,
+		LeakRate:    demoLeakRate,
+		StallEvery:  demoStallEvery,
+		StallLength: demoStallLength,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if demoDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, demoDuration)
+		defer cancel()
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nShutting down gracefully...")
+		cancel()
+	}()
+
+	return monitor.NewWithSource(cfg, source).Start(ctx)
+}
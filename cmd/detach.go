@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"stackpulse/internal/config"
+)
+
+// detachedEnv marks the background child started by watch --detach
+const detachedEnv = "STACKPULSE_DETACHED"
+
+func defaultPidFile() string {
+	return filepath.Join(os.TempDir(), "stackpulse.pid")
+}
+
+func defaultDetachLog() string {
+	return filepath.Join(os.TempDir(), "stackpulse.log")
+}
+
+// detachWatch re-runs the current watch command in the background with its
+// output appended to logPath, records the child's PID in pidPath, and
+// returns without waiting for it.
+func detachWatch(pidPath, logPath string) error {
+	if !detachSupported {
+		return fmt.Errorf("--detach is not supported on this platform; run watch under a service manager instead")
+	}
+	if pid, err := config.ReadPidFile(pidPath); err == nil {
+		if alive, _ := process.PidExists(int32(pid)); alive {
+			return fmt.Errorf("a detached watcher is already running (PID %d, %s)", pid, pidPath)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the stackpulse binary: %w", err)
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if arg == "--detach" || strings.HasPrefix(arg, "--detach=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	child := exec.Command(executable, args...)
+	child.Env = append(os.Environ(), detachedEnv+"=1")
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachAttr()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start background watcher: %w", err)
+	}
+
+	pid := child.Process.Pid
+	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		child.Process.Kill()
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	child.Process.Release()
+
+	fmt.Printf("StackPulse is watching in the background (PID %d)\n", pid)
+	fmt.Printf("Logs: %s\n", logPath)
+	fmt.Printf("Stop it with: stackpulse stop --pidfile %s\n", pidPath)
+	return nil
+}
+
+// removeOwnPidFile deletes pidPath if it still names this process, so a
+// detached watcher cleans up after itself without touching a newer one.
+func removeOwnPidFile(pidPath string) {
+	if pid, err := config.ReadPidFile(pidPath); err == nil && pid == os.Getpid() {
+		os.Remove(pidPath)
+	}
+}
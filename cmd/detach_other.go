@@ -0,0 +1,21 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// Background sessions rely on setsid; elsewhere watch must be run under a
+// service manager.
+const detachSupported = false
+
+func detachAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// terminate stops the watcher; there is no graceful signal to send here.
+func terminate(proc *os.Process) error {
+	return proc.Kill()
+}
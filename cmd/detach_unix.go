@@ -0,0 +1,21 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+const detachSupported = true
+
+// detachAttr starts the child in its own session so it survives the
+// terminal closing and does not receive the shell's job-control signals.
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// terminate asks the watcher to shut down gracefully.
+func terminate(proc *os.Process) error {
+	return proc.Signal(syscall.SIGTERM)
+}
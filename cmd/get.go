@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+	"stackpulse/internal/types"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <metric>",
+	Short: "Print a single metric value for use in shell scripts",
+	Long: `Collect one sample and print a single metric as a bare value. The metric is
+a dotted path into the status, using the field names of the JSON output
+(matched case-insensitively).
+
+Examples:
+  stackpulse get cpu --pid 1234
+  stackpulse get memory.rss --port 3000
+  echo "lag: $(stackpulse get eventloop.lag --pid 1234)ms"`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runGet,
+	SilenceUsage: true,
+}
+
+var (
+	getPID         int
+	getPort        int
+	getInspectPort int
+	getInspectHost string
+	getTimeout     time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+
+	getCmd.Flags().IntVar(&getPID, "pid", 0, "Process ID to sample")
+	getCmd.Flags().IntVar(&getPort, "port", 0, "Port of the service to sample")
+	getCmd.Flags().IntVar(&getInspectPort, "inspect-port", 9229, "V8 inspector port")
+	getCmd.Flags().StringVar(&getInspectHost, "inspect-host", "localhost", "V8 inspector host, or a ws:// or wss:// URL with optional user:password for basic auth")
+	getCmd.Flags().DurationVar(&getTimeout, "timeout", 10*time.Second, "Give up if the sample takes longer than this")
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	cfg := &config.ServiceConfig{
+		PID:             getPID,
+		Port:            getPort,
+		InspectPort:     getInspectPort,
+		InspectHost:     getInspectHost,
+		HeapLimit:       "150MB",
+		CPUThreshold:    70,
+		PollingInterval: 100 * time.Millisecond,
+
+		GCReclaimThreshold: 0.1,
+		GCReclaimCount:     3,
+
+		HistorySize: config.DefaultHistorySize,
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Output must be the bare value; collection warnings would only get in
+	// the way of $(...) capture
+	log.SetOutput(io.Discard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), getTimeout)
+	defer cancel()
+
+	status, err := collectSample(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+
+	value, err := lookupMetric(status, args[0])
+	if err != nil {
+		return err
+	}
+	formatted, err := formatMetric(args[0], value)
+	if err != nil {
+		return err
+	}
+	fmt.Println(formatted)
+	return nil
+}
+
+// metricAliases maps short names to their full path in the status
+var metricAliases = map[string]string{
+	"cpu":    "cpu.usage",
+	"memory": "memory.rss",
+	"rss":    "memory.rss",
+	"lag":    "eventLoop.lag",
+}
+
+// lookupMetric selects the value at a dotted path such as "memory.rss" in
+// the JSON form of status. Numbers are returned as float64.
+func lookupMetric(status *types.Status, path string) (interface{}, error) {
+	if alias, ok := metricAliases[strings.ToLower(path)]; ok {
+		path = alias
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode status: %w", err)
+	}
+	var node interface{}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to decode status: %w", err)
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		fields, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("metric %q: %s is not an object", path, part)
+		}
+		next, found := fields[part]
+		if !found {
+			for key, value := range fields {
+				if strings.EqualFold(key, part) {
+					next, found = value, true
+					break
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown metric %q: no field %s (available: %s)", path, part, strings.Join(fieldNames(fields), ", "))
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// formatMetric renders a single value from lookupMetric for the shell.
+func formatMetric(path string, node interface{}) (string, error) {
+	switch value := node.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case nil:
+		return "", fmt.Errorf("metric %q is not available for this process", path)
+	case map[string]interface{}:
+		return "", fmt.Errorf("metric %q is not a single value (fields: %s)", path, strings.Join(fieldNames(value), ", "))
+	default:
+		return "", fmt.Errorf("metric %q is not a single value", path)
+	}
+}
+
+func fieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
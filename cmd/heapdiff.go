@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/metrics"
+)
+
+var heapdiffCmd = &cobra.Command{
+	Use:   "heapdiff",
+	Short: "Report which constructors grew between two heap snapshots",
+	Long: `Take two heap snapshots of a Node.js process an interval apart and list the
+constructors whose retained size and instance count grew the most in between.
+
+Examples:
+  stackpulse heapdiff --inspect-port 9229 --interval 60s
+  stackpulse heapdiff --interval 5m --top 10`,
+	RunE: runHeapdiff,
+}
+
+var (
+	heapdiffInspectPort int
+	heapdiffInspectHost string
+	heapdiffInterval    time.Duration
+	heapdiffTop         int
+)
+
+func init() {
+	rootCmd.AddCommand(heapdiffCmd)
+
+	heapdiffCmd.Flags().IntVar(&heapdiffInspectPort, "inspect-port", 9229, "V8 inspector port")
+	heapdiffCmd.Flags().StringVar(&heapdiffInspectHost, "inspect-host", "localhost", "V8 inspector host, or a ws:// or wss:// URL with optional user:password for basic auth")
+	heapdiffCmd.Flags().DurationVar(&heapdiffInterval, "interval", time.Minute, "Time between the two snapshots")
+	heapdiffCmd.Flags().IntVar(&heapdiffTop, "top", 20, "Number of constructors to report (0 for all)")
+}
+
+func runHeapdiff(cmd *cobra.Command, args []string) error {
+	if heapdiffInterval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	collector := metrics.NewCollector(&config.ServiceConfig{InspectPort: heapdiffInspectPort, InspectHost: heapdiffInspectHost})
+
+	fmt.Println("Taking first heap snapshot...")
+	before, err := collector.HeapConstructors(ctx, heapdiffInspectPort)
+	if err != nil {
+		return fmt.Errorf("failed to take first heap snapshot: %w", err)
+	}
+
+	fmt.Printf("Waiting %s before the second snapshot...\n", heapdiffInterval)
+	select {
+	case <-time.After(heapdiffInterval):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	fmt.Println("Taking second heap snapshot...")
+	after, err := collector.HeapConstructors(ctx, heapdiffInspectPort)
+	if err != nil {
+		return fmt.Errorf("failed to take second heap snapshot: %w", err)
+	}
+
+	diffs := metrics.DiffConstructors(before, after)
+	if heapdiffTop > 0 && len(diffs) > heapdiffTop {
+		diffs = diffs[:heapdiffTop]
+	}
+	fmt.Println()
+	display.PrintHeapDiff(os.Stdout, diffs)
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/monitor"
+	"stackpulse/internal/types"
+)
+
+// onceTimeout bounds the single collection of watch --once
+const onceTimeout = 30 * time.Second
+
+// cpuSampleWindow is how long a one-shot sample measures CPU usage over
+const cpuSampleWindow = 500 * time.Millisecond
+
+// collectSample collects one status for the one-shot commands. CPU usage is
+// measured between samples, so a second sample is taken after a short
+// window when the first has none yet.
+func collectSample(ctx context.Context, cfg *config.ServiceConfig) (*types.Status, error) {
+	m := monitor.NewHeadless(cfg)
+	status, err := m.Collect(ctx)
+	if err != nil || !status.CPU.FirstSample {
+		return status, err
+	}
+
+	select {
+	case <-time.After(cpuSampleWindow):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.Collect(ctx)
+}
+
+// runOnce collects a single sample, prints it, and checks each requirement
+// against it. Any failed requirement makes the command fail, listing all of
+// them, so CI jobs can gate on specific SLOs; so does any critical or
+// emergency alert, for use as a health check.
+func runOnce(cfg *config.ServiceConfig, requirements []config.Requirement) error {
+	ctx, cancel := context.WithTimeout(context.Background(), onceTimeout)
+	defer cancel()
+
+	status, err := collectSample(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	switch cfg.Output {
+	case config.OutputJSON:
+		renderer := display.NewJSONRenderer(os.Stdout)
+		renderer.SetPretty(cfg.JSONPretty)
+		renderer.Update(status)
+	case config.OutputInflux:
+		display.NewInfluxRenderer(os.Stdout).Update(status)
+	default:
+		display.NewLineRenderer(os.Stdout).Update(status)
+	}
+
+	var critical []string
+	for _, alert := range status.Alerts {
+		if alert.Severity.Rank() >= types.SeverityCritical.Rank() {
+			critical = append(critical, alert.Message)
+		}
+	}
+
+	var failed []string
+	for _, req := range requirements {
+		node, err := lookupMetric(status, req.Metric)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", req.Spec, err)
+			failed = append(failed, req.Spec)
+			continue
+		}
+		value, ok := node.(float64)
+		if !ok {
+			fmt.Printf("FAIL %s: %s is not a number\n", req.Spec, req.Metric)
+			failed = append(failed, req.Spec)
+			continue
+		}
+
+		actual := strconv.FormatFloat(value, 'f', -1, 64)
+		if req.Holds(value) {
+			fmt.Printf("PASS %s (actual: %s)\n", req.Spec, actual)
+			continue
+		}
+		fmt.Printf("FAIL %s (actual: %s)\n", req.Spec, actual)
+		failed = append(failed, req.Spec)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d requirements failed: %v", len(failed), len(requirements), failed)
+	}
+	if len(critical) > 0 {
+		return fmt.Errorf("critical alerts firing: %s", strings.Join(critical, "; "))
+	}
+	return nil
+}
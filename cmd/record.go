@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+	"stackpulse/internal/monitor"
+	"stackpulse/internal/types"
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record a monitoring session to a file for replay",
+	Long: `Monitor a process without the dashboard and append every status to a file as
+JSON lines, in the same format as watch --jsonl. Play the session back later
+with replay, or check alert rules against it with test-alerts.
+
+Examples:
+  stackpulse record --pid 1234 --out session.jsonl
+  stackpulse record --port 3000 --polling-ms 500 --out session.jsonl --duration 10m
+  stackpulse replay session.jsonl`,
+	RunE:         runRecord,
+	SilenceUsage: true,
+}
+
+var (
+	recordPID         int
+	recordPort        int
+	recordInspectPort int
+	recordInspectHost string
+	recordPollingMs   int
+	recordOut         string
+	recordDuration    time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().IntVar(&recordPID, "pid", 0, "Process ID to record")
+	recordCmd.Flags().IntVar(&recordPort, "port", 0, "Port of the service to record")
+	recordCmd.Flags().IntVar(&recordInspectPort, "inspect-port", 9229, "V8 inspector port")
+	recordCmd.Flags().StringVar(&recordInspectHost, "inspect-host", "localhost", "V8 inspector host, or a ws:// or wss:// URL with optional user:password for basic auth")
+	recordCmd.Flags().IntVar(&recordPollingMs, "polling-ms", 100, "Polling interval in milliseconds")
+	recordCmd.Flags().StringVar(&recordOut, "out", "", "File to append the session to")
+	recordCmd.Flags().DurationVar(&recordDuration, "duration", 0, "Stop recording after this long (0 records until interrupted)")
+	recordCmd.MarkFlagRequired("out")
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	cfg := &config.ServiceConfig{
+		Host:            "127.0.0.1",
+		PID:             recordPID,
+		Port:            recordPort,
+		InspectPort:     recordInspectPort,
+		InspectHost:     recordInspectHost,
+		HeapLimit:       "150MB",
+		CPUThreshold:    70,
+		PollingInterval: time.Duration(recordPollingMs) * time.Millisecond,
+		JSONLPath:       recordOut,
+
+		GCReclaimThreshold: 0.1,
+		GCReclaimCount:     3,
+
+		HistorySize: config.DefaultHistorySize,
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if recordDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, recordDuration)
+		defer cancel()
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	// Count samples for the summary; the monitor writes the file itself
+	mon := monitor.NewHeadless(cfg)
+	updates := make(chan types.Status, 16)
+	mon.Subscribe(updates)
+	samples := make(chan int)
+	go func() {
+		count := 0
+		for range updates {
+			count++
+		}
+		samples <- count
+	}()
+
+	fmt.Fprintf(os.Stderr, "Recording to %s, press Ctrl+C to stop\n", recordOut)
+	err := mon.Start(ctx)
+	mon.Unsubscribe(updates)
+	close(updates)
+	fmt.Fprintf(os.Stderr, "Recorded %d samples to %s\n", <-samples, recordOut)
+	return err
+}
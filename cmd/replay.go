@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/export"
+	"stackpulse/internal/types"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <session.jsonl>",
+	Short: "Play a recorded session back on the dashboard",
+	Long: `Play a session written by record (or watch --jsonl) back on the dashboard,
+keeping the time between the recorded samples. --speed scales playback.
+
+Ctrl+C pauses playback; press Enter to resume or Ctrl+C again to quit.
+
+Examples:
+  stackpulse replay session.jsonl
+  stackpulse replay session.jsonl --speed 4`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runReplay,
+	SilenceUsage: true,
+}
+
+var (
+	replaySpeed  float64
+	replayGlyphs string
+	replayUnits  string
+	replayColor  string
+	replayTheme  string
+
+	replayNoSparkline bool
+)
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().Float64Var(&replaySpeed, "speed", 1, "Playback speed relative to the recording, e.g. 2 for twice as fast")
+	replayCmd.Flags().StringVar(&replayGlyphs, "glyphs", "emoji", "Status indicators on the dashboard: emoji, unicode or ascii")
+	replayCmd.Flags().StringVar(&replayUnits, "units", "auto", "Byte sizes on the dashboard: si (MB), iec (MiB) or auto (scaled binary units)")
+	replayCmd.Flags().StringVar(&replayColor, "color", "auto", "Color the dashboard: auto (terminals without NO_COLOR), always or never")
+	replayCmd.Flags().StringVar(&replayTheme, "theme", "dark", "Dashboard palette: dark or light for light terminal backgrounds")
+	replayCmd.Flags().BoolVar(&replayNoSparkline, "no-sparkline", false, "Leave out the dashboard's trend column, for terminals without block characters")
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	if replaySpeed <= 0 {
+		return fmt.Errorf("speed must be greater than 0")
+	}
+	glyphs, ok := display.LookupGlyphs(replayGlyphs)
+	if !ok {
+		return fmt.Errorf("unknown glyph set %q (expected emoji, unicode or ascii)", replayGlyphs)
+	}
+	units, ok := display.LookupUnits(replayUnits)
+	if !ok {
+		return fmt.Errorf("unknown units %q (expected si, iec or auto)", replayUnits)
+	}
+	colorMode, ok := display.LookupColorMode(replayColor)
+	if !ok {
+		return fmt.Errorf("unknown color mode %q (expected auto, always or never)", replayColor)
+	}
+	theme, ok := display.LookupTheme(replayTheme)
+	if !ok {
+		return fmt.Errorf("unknown theme %q (expected dark or light)", replayTheme)
+	}
+	display.SetColorMode(colorMode)
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	defer file.Close()
+	reader := export.NewJSONLReader(file)
+
+	dashboard := display.NewDashboard()
+	dashboard.SetGlyphs(glyphs)
+	dashboard.SetUnits(units)
+	dashboard.SetTheme(theme)
+	dashboard.SetSparklines(!replayNoSparkline)
+
+	// The trend column draws from the samples played so far
+	var played []types.Status
+	dashboard.SetHistory(func() []types.Status { return played })
+
+	// Interrupts pause instead of exiting; Enter resumes
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	enter := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			enter <- struct{}{}
+		}
+	}()
+
+	status, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("no statuses in %s", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	first := status.Timestamp
+
+	samples := 0
+	for {
+		played = append(played, *status)
+		if len(played) > config.DefaultHistorySize {
+			played = played[1:]
+		}
+		dashboard.Update(status)
+		samples++
+
+		next, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		wait := time.Duration(float64(next.Timestamp.Sub(status.Timestamp)) / replaySpeed)
+		if !replayWait(wait, sigChan, enter, status.Timestamp) {
+			fmt.Printf("\nStopped after %d samples (%s into the session)\n", samples, status.Timestamp.Sub(first).Round(time.Millisecond))
+			return nil
+		}
+		status = next
+	}
+
+	fmt.Printf("\nReplayed %d samples covering %s\n", samples, status.Timestamp.Sub(first).Round(time.Millisecond))
+	return nil
+}
+
+// replayWait sleeps for wait, pausing on an interrupt until Enter resumes
+// the rest of the wait. It returns false when playback should stop: a
+// second interrupt while paused, or SIGTERM.
+func replayWait(wait time.Duration, sigChan <-chan os.Signal, enter <-chan struct{}, at time.Time) bool {
+	deadline := time.Now().Add(wait)
+	for {
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-timer.C:
+			return true
+		case <-enter:
+			// Enter while playing does nothing
+			timer.Stop()
+			continue
+		case sig := <-sigChan:
+			timer.Stop()
+			if sig == syscall.SIGTERM {
+				return false
+			}
+		}
+
+		remaining := time.Until(deadline)
+		fmt.Printf("\nPaused at %s: press Enter to resume or Ctrl+C to quit\n", at.Format("15:04:05.000"))
+		select {
+		case <-enter:
+			deadline = time.Now().Add(remaining)
+		case <-sigChan:
+			return false
+		}
+	}
+}
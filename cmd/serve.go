@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/api"
+	"stackpulse/internal/config"
+	"stackpulse/internal/monitor"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Monitor a process and serve its metrics as a JSON API",
+	Long: `Monitor a process without the dashboard and serve the results over HTTP:
+
+  GET /status                                latest status
+  GET /metrics/history?metric=cpu&since=5m  samples of one metric, oldest first
+  GET /alerts                                active alerts
+  GET /metrics                               latest status for Prometheus
+
+History metrics are cpu, rss, heap, lag, elu, gc and handles, and cover the
+samples kept in memory: the last --retain-samples, taken within --retain-for.
+
+Examples:
+  stackpulse serve --pid 1234 --api-port 8080
+  stackpulse serve --port 3000 --api-host 0.0.0.0 --api-port 8080`,
+	RunE:         runServe,
+	SilenceUsage: true,
+}
+
+var (
+	servePID         int
+	servePort        int
+	serveName        string
+	serveInspectPort int
+	serveInspectHost string
+	servePollingMs   int
+	serveAPIHost     string
+	serveAPIPort     int
+	serveRetain      int
+	serveRetainFor   time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().IntVar(&servePID, "pid", 0, "Process ID to monitor")
+	serveCmd.Flags().IntVar(&servePort, "port", 0, "Port of the service to monitor")
+	serveCmd.Flags().StringVar(&serveName, "name", "", "Monitor the one process whose name or command line matches this regular expression")
+	serveCmd.Flags().IntVar(&serveInspectPort, "inspect-port", 9229, "V8 inspector port")
+	serveCmd.Flags().StringVar(&serveInspectHost, "inspect-host", "localhost", "V8 inspector host, or a ws:// or wss:// URL with optional user:password for basic auth")
+	serveCmd.Flags().IntVar(&servePollingMs, "polling-ms", 1000, "Polling interval in milliseconds")
+	serveCmd.Flags().StringVar(&serveAPIHost, "api-host", "127.0.0.1", "Address the API listens on")
+	serveCmd.Flags().IntVar(&serveAPIPort, "api-port", 8080, "Port the API listens on")
+	serveCmd.Flags().IntVar(&serveRetain, "retain-samples", config.DefaultRetainSamples, "Most samples kept in memory for history queries")
+	serveCmd.Flags().DurationVar(&serveRetainFor, "retain-for", config.DefaultRetainFor, "How long samples are kept in memory for history queries")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg := &config.ServiceConfig{
+		Host:            "127.0.0.1",
+		PID:             servePID,
+		Port:            servePort,
+		ProcessName:     serveName,
+		InspectPort:     serveInspectPort,
+		InspectHost:     serveInspectHost,
+		HeapLimit:       "150MB",
+		CPUThreshold:    70,
+		PollingInterval: time.Duration(servePollingMs) * time.Millisecond,
+
+		GCReclaimThreshold: 0.1,
+		GCReclaimCount:     3,
+
+		HistorySize:   config.DefaultHistorySize,
+		RetainSamples: serveRetain,
+		RetainFor:     serveRetainFor,
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(serveAPIHost, strconv.Itoa(serveAPIPort)))
+	if err != nil {
+		return fmt.Errorf("failed to start API server: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	mon := monitor.NewHeadless(cfg)
+	server := api.NewServer(mon.Samples(), mon)
+	httpServer := &http.Server{Handler: server.Handler()}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Warning: API server stopped: %v", err)
+		}
+	}()
+	defer httpServer.Close()
+
+	fmt.Fprintf(os.Stderr, "Serving the API on http://%s, press Ctrl+C to stop\n", listener.Addr())
+	return mon.Start(ctx)
+}
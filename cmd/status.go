@@ -2,28 +2,53 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"stackpulse/internal/monitor"
+	"stackpulse/internal/api"
+	"stackpulse/internal/display"
+	"stackpulse/internal/types"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show current resource usage of monitored services",
-	Long:  `Display real-time resource usage statistics for all monitored Node.js services.`,
-	RunE:  runStatus,
+	Short: "Show current resource usage of a running watch",
+	Long: `Display the latest sample of a watch started with --socket.
+
+Examples:
+  stackpulse watch --port 3000 --socket &
+  stackpulse status
+  stackpulse status --socket /tmp/api.sock`,
+	RunE: runStatus,
 }
 
+var statusSocket string
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusSocket, "socket", defaultSocketPath(), "Control socket of the running watch")
+}
+
+// defaultSocketPath is the control socket used when --socket is given
+// without a path: in the user's runtime directory when there is one.
+func defaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "stackpulse.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("stackpulse-%d.sock", os.Getuid()))
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
-	status, err := monitor.GetCurrentStatus()
-	if err != nil {
+	// A missing watcher is not a usage error
+	cmd.SilenceUsage = true
+
+	var status types.Status
+	if err := api.SendCommand(statusSocket, "status", "", &status); err != nil {
 		return fmt.Errorf("failed to get status: %w", err)
 	}
 
-	monitor.DisplayStatus(status)
+	display.PrintStatus(os.Stdout, &status)
 	return nil
-}
\ No newline at end of file
+}
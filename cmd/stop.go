@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/spf13/cobra"
+	"stackpulse/internal/config"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop a watcher started with watch --detach",
+	Long: `Stop the background watcher recorded in the pidfile and wait for it to exit.
+
+Examples:
+  stackpulse stop
+  stackpulse stop --pidfile /run/stackpulse.pid`,
+	RunE: runStop,
+}
+
+var (
+	stopPidFile string
+	stopTimeout time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+
+	stopCmd.Flags().StringVar(&stopPidFile, "pidfile", defaultPidFile(), "Pidfile written by watch --detach")
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", 10*time.Second, "How long to wait for the watcher to exit")
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	pid, err := config.ReadPidFile(stopPidFile)
+	if err != nil {
+		return fmt.Errorf("no detached watcher found: %w", err)
+	}
+
+	if alive, _ := process.PidExists(int32(pid)); !alive {
+		os.Remove(stopPidFile)
+		return fmt.Errorf("watcher PID %d is not running (removed stale pidfile)", pid)
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find watcher PID %d: %w", pid, err)
+	}
+	if err := terminate(proc); err != nil {
+		return fmt.Errorf("failed to stop watcher PID %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if alive, _ := process.PidExists(int32(pid)); !alive {
+			os.Remove(stopPidFile)
+			fmt.Printf("Stopped watcher (PID %d)\n", pid)
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("watcher PID %d did not exit within %s", pid, stopTimeout)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/store"
+	"stackpulse/internal/types"
+)
+
+// metricStats are the statistics of one metric over a watch --duration run,
+// in the units of types.Status.
+type metricStats struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	Max  float64 `json:"max"`
+	P95  float64 `json:"p95"`
+}
+
+// runSummary is printed when a watch --duration run ends. Start and End are
+// those of the samples it covers, which the store's retention may have cut
+// short of the whole run.
+type runSummary struct {
+	Start   time.Time              `json:"start"`
+	End     time.Time              `json:"end"`
+	Samples int                    `json:"samples"`
+	Metrics map[string]metricStats `json:"metrics"`
+}
+
+// summarizeRun computes the statistics of every store metric over samples.
+// The first CPU sample of a process has no usage yet and is left out.
+func summarizeRun(samples []types.Status) runSummary {
+	summary := runSummary{Samples: len(samples), Metrics: make(map[string]metricStats)}
+	if len(samples) == 0 {
+		return summary
+	}
+	summary.Start = samples[0].Timestamp
+	summary.End = samples[len(samples)-1].Timestamp
+
+	for _, metric := range store.Metrics() {
+		var values []float64
+		for i := range samples {
+			if metric == "cpu" && samples[i].CPU.FirstSample {
+				continue
+			}
+			value, _ := store.Value(metric, &samples[i])
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		stats := metricStats{Min: math.Inf(1), Max: math.Inf(-1)}
+		sum := 0.0
+		for _, v := range values {
+			stats.Min = math.Min(stats.Min, v)
+			stats.Max = math.Max(stats.Max, v)
+			sum += v
+		}
+		stats.Mean = sum / float64(len(values))
+
+		sort.Float64s(values)
+		stats.P95 = values[min(int(float64(len(values))*0.95), len(values)-1)]
+		summary.Metrics[metric] = stats
+	}
+	return summary
+}
+
+// printRunSummary writes the summary of the samples taken since start: a
+// table, or with JSON output one final object after the status lines.
+func printRunSummary(out io.Writer, samples *store.Store, start time.Time, cfg *config.ServiceConfig) error {
+	summary := summarizeRun(samples.Since(start))
+
+	if cfg.Output == config.OutputJSON {
+		return json.NewEncoder(out).Encode(struct {
+			Summary runSummary `json:"summary"`
+		}{summary})
+	}
+
+	if summary.Samples == 0 {
+		fmt.Fprintln(out, "No samples collected")
+		return nil
+	}
+	fmt.Fprintf(out, "\nSummary of %d samples over %s\n", summary.Samples, summary.End.Sub(summary.Start).Round(time.Millisecond))
+
+	units, _ := display.LookupUnits(cfg.Units)
+	format := func(metric string, v float64) string {
+		switch metric {
+		case "cpu", "elu":
+			return fmt.Sprintf("%.1f%%", v)
+		case "rss", "heap":
+			return display.FormatBytes(uint64(v), units)
+		case "lag", "gc":
+			return fmt.Sprintf("%.2f ms", v)
+		}
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Metric", "Min", "Avg", "Max", "P95"})
+	table.SetBorder(true)
+	for _, metric := range store.Metrics() {
+		stats, ok := summary.Metrics[metric]
+		if !ok {
+			continue
+		}
+		table.Append([]string{
+			metric,
+			format(metric, stats.Min),
+			format(metric, stats.Mean),
+			format(metric, stats.Max),
+			format(metric, stats.P95),
+		})
+	}
+	table.Render()
+	return nil
+}
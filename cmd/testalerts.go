@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"stackpulse/internal/alerts"
+	"stackpulse/internal/config"
+	"stackpulse/internal/export"
+	"stackpulse/internal/types"
+)
+
+var testAlertsCmd = &cobra.Command{
+	Use:   "test-alerts",
+	Short: "Replay a recorded capture through the alert rules",
+	Long: `Replay statuses recorded with watch --jsonl through the alert engine and print
+every alert that would have fired, resolved or escalated, at the time of the
+sample that caused it. No process is monitored, so rules can be tuned
+against real history before they are deployed.
+
+Settings missing from the rules file keep the defaults of watch.
+
+Examples:
+  stackpulse test-alerts --rules rules.yaml --input capture.jsonl
+  stackpulse test-alerts --input capture.jsonl`,
+	RunE: runTestAlerts,
+}
+
+var (
+	testAlertsRules string
+	testAlertsInput string
+)
+
+func init() {
+	rootCmd.AddCommand(testAlertsCmd)
+
+	testAlertsCmd.Flags().StringVar(&testAlertsRules, "rules", "", "Rules file (YAML or JSON) with alert settings such as cpuThreshold, bands and relative")
+	testAlertsCmd.Flags().StringVar(&testAlertsInput, "input", "", "Capture written by watch --jsonl")
+	testAlertsCmd.MarkFlagRequired("input")
+}
+
+func runTestAlerts(cmd *cobra.Command, args []string) error {
+	cfg := &config.ServiceConfig{
+		HeapLimit:       "150MB",
+		CPUThreshold:    70,
+		PollingInterval: 100 * time.Millisecond,
+
+		DeoptRateThreshold: 5,
+
+		GCReclaimThreshold: 0.1,
+		GCReclaimCount:     3,
+
+		HistorySize: config.DefaultHistorySize,
+
+		TrackGrowthThreshold: 50,
+		StuckWindow:          30 * time.Second,
+		WarmupFactor:         2,
+
+		RestartLimit:  3,
+		RestartWindow: 5 * time.Minute,
+
+		BaselineWindow: 10 * time.Minute,
+		NetworkSustain: 30 * time.Second,
+	}
+	if testAlertsRules != "" {
+		if err := cfg.LoadRules(testAlertsRules); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(testAlertsInput)
+	if err != nil {
+		return fmt.Errorf("failed to open capture: %w", err)
+	}
+	defer file.Close()
+	reader := export.NewJSONLReader(file)
+
+	// Replayed statuses are checked at the time they were recorded, so
+	// windows and warmup behave as they would have live
+	var now time.Time
+	manager := alerts.NewManager()
+	manager.SetClock(func() time.Time { return now })
+
+	out := os.Stdout
+	severities := make(map[string]types.AlertSeverity)
+	var samples, fired int
+	var first time.Time
+	for {
+		status, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if samples == 0 {
+			first = status.Timestamp
+			if cfg.PID == 0 {
+				cfg.PID = status.PID
+			}
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("invalid rules: %w", err)
+			}
+		}
+		samples++
+		now = status.Timestamp
+
+		alertList := manager.CheckThresholds(status, cfg)
+		for _, event := range manager.Events() {
+			if event.Resolved {
+				printReplayedAlert(out, now, "RESOLVED", event.Alert)
+				delete(severities, event.Alert.IncidentID)
+				continue
+			}
+			fired++
+			printReplayedAlert(out, now, "FIRED", event.Alert)
+			severities[event.Alert.IncidentID] = event.Alert.Severity
+		}
+		for _, alert := range alertList {
+			if alert.Severity.Rank() > severities[alert.IncidentID].Rank() {
+				printReplayedAlert(out, now, "ESCALATED", alert)
+				severities[alert.IncidentID] = alert.Severity
+			}
+		}
+	}
+
+	if samples == 0 {
+		return fmt.Errorf("no statuses in %s", testAlertsInput)
+	}
+	fmt.Fprintf(out, "\n%d alerts fired over %d samples (%s to %s), %d still active at the end\n",
+		fired, samples, first.Format(time.RFC3339), now.Format(time.RFC3339), len(severities))
+	return nil
+}
+
+func printReplayedAlert(out io.Writer, at time.Time, event string, alert types.Alert) {
+	fmt.Fprintf(out, "%s %-9s [%s] %s (incident %s)\n",
+		at.Format(time.RFC3339), event, alert.Severity, alert.Message, alert.IncidentID)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at link time, e.g.
+//
+//	go build -ldflags "-X stackpulse/cmd.version=v1.2.0 -X stackpulse/cmd.commit=$(git rev-parse --short HEAD)"
+//
+// make build sets all three from git.
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build details",
+	Long: `Print the version, git commit and build date of this binary, with the Go
+version and platform it was built for. Include the output in bug reports.
+
+Examples:
+  stackpulse version
+  stackpulse --version`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	rootCmd.Version = version
+	rootCmd.SetVersionTemplate(versionInfo())
+}
+
+// versionInfo formats the build metadata. Without ldflags, a commit
+// recorded by the go toolchain (building inside a git checkout) is used.
+func versionInfo() string {
+	rev := commit
+	if rev == "unknown" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range info.Settings {
+				if s.Key == "vcs.revision" && len(s.Value) >= 12 {
+					rev = s.Value[:12]
+				}
+			}
+		}
+	}
+
+	return fmt.Sprintf("stackpulse %s\n  commit:   %s\n  built:    %s\n  go:       %s\n  platform: %s/%s\n",
+		version, rev, buildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
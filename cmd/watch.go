@@ -3,14 +3,22 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"stackpulse/internal/api"
 	"stackpulse/internal/monitor"
 	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/metrics"
+	"stackpulse/internal/types"
 )
 
 var watchCmd = &cobra.Command{
@@ -27,11 +35,149 @@ Examples:
 var (
 	host          string
 	port          int
+	bindAddr      string
+	portMismatch  string
 	pid           int
+	processName   string
+	watchAll      bool
+	targetPidFile string
+	containerID   string
+	waitProcess   bool
 	heapLimit     string
 	cpuThreshold  float64
+	cpuNormalize  string
+	cpuSmoothing  float64
+	cpuValue      string
 	pollingMs     int
+	historySize   int
+	retainSamples int
+	retainFor     time.Duration
 	inspectPort   int
+	inspectHost   string
+	pollAlign     bool
+	pollingJitter float64
+	shmFile       string
+	metricsFile   string
+
+	jsonlFile      string
+	csvFile        string
+	alertDBFile    string
+	rotateSize     string
+	rotateKeep     int
+	rotateCompress bool
+
+	influxURL    string
+	influxToken  string
+	influxBucket string
+	influxOrg    string
+
+	inspectTimeout time.Duration
+	inspectRetries int
+	groupIntervals []string
+	collectGroups  []string
+
+	exitOnRecovery bool
+	recoveryPeriod time.Duration
+
+	redrawEpsilon     float64
+	redrawMaxInterval time.Duration
+	refreshMs         int
+	smoothSamples     int
+	output            string
+	jsonPretty        bool
+	glyphs            string
+	units             string
+	colorMode         string
+	theme             string
+	noSparkline       bool
+	tui               bool
+
+	gcSource       string
+	compareRuntime bool
+	deoptThreshold float64
+	severityBands  []string
+
+	heapThreshold        string
+	lagThreshold         string
+	utilizationThreshold string
+	gcThreshold          string
+	handlesThreshold     string
+
+	relativeThresholds []string
+	baselineWindow     time.Duration
+	anomalyMetrics     []string
+	anomalyK           float64
+
+	customMetrics []string
+	stuckMetric   string
+	stuckWindow   time.Duration
+
+	warmup       time.Duration
+	warmupFactor float64
+
+	alertHistory     int
+	resolveAfter     time.Duration
+	alertConsecutive int
+
+	summaryEvery time.Duration
+
+	maxFailures  int
+	onFailureCmd string
+
+	restartLimit  int
+	restartWindow time.Duration
+
+	fdWarnRatio     float64
+	fdCriticalRatio float64
+
+	gcReclaimThreshold float64
+	gcReclaimCount     int
+	gcRateThreshold    float64
+	gcRateWindow       time.Duration
+
+	trackConstructors []string
+	trackInterval     time.Duration
+	trackGrowth       float64
+
+	collectNetwork   bool
+	networkThreshold float64
+	networkSustain   time.Duration
+
+	leakWindow time.Duration
+	leakSlope  string
+
+	once         bool
+	requirements []string
+
+	watchDuration time.Duration
+
+	detach    bool
+	pidFile   string
+	detachLog string
+
+	k8sEvents  bool
+	bell       bool
+	desktop    bool
+	apiAddr    string
+	socketPath string
+
+	k8sEventsMinSeverity string
+	bellMinSeverity      string
+	slackWebhook         string
+	slackMinSeverity     string
+
+	alertWebhook            string
+	alertWebhookContentType string
+	alertWebhookToken       string
+
+	pagerDutyRoutingKey  string
+	pagerDutyMinSeverity string
+	notifyCooldown       time.Duration
+
+	captureOnCritical bool
+	captureTypes      []string
+	captureDir        string
+	captureDuration   time.Duration
 )
 
 func init() {
@@ -39,28 +185,400 @@ func init() {
 	
 	watchCmd.Flags().StringVar(&host, "host", "127.0.0.1", "Host to monitor")
 	watchCmd.Flags().IntVar(&port, "port", 0, "Port to monitor")
+	watchCmd.Flags().StringVar(&portMismatch, "port-mismatch", "warn", "When both --pid and --port are given and the PID does not own the port: warn, error or ignore")
+	watchCmd.Flags().StringVar(&bindAddr, "bind-addr", "", "With --port, only match a process bound to this local address")
 	watchCmd.Flags().IntVar(&pid, "pid", 0, "Process ID to monitor")
+	watchCmd.Flags().BoolVar(&waitProcess, "wait", false, "With --pid, keep running when the process exits until it appears again instead of stopping")
+	watchCmd.Flags().StringVar(&processName, "name", "", "Monitor the one process whose name or command line matches this regular expression")
+	watchCmd.Flags().BoolVar(&watchAll, "all", false, "With --name, monitor every matching process on a combined dashboard instead of requiring one match")
+	watchCmd.Flags().StringVar(&targetPidFile, "target-pidfile", "", "Monitor the process whose PID a process manager wrote to this file, read again when it exits")
+	watchCmd.Flags().StringVar(&containerID, "container", "", "Monitor the Node.js process of this Docker container (ID or name), or of a containerd or Kubernetes container by ID, through its host PID")
 	watchCmd.Flags().StringVar(&heapLimit, "heap-limit", "150MB", "Heap memory limit threshold")
-	watchCmd.Flags().Float64Var(&cpuThreshold, "cpu-threshold", 70.0, "CPU usage threshold percentage")
-	watchCmd.Flags().IntVar(&pollingMs, "polling-ms", 100, "Polling interval in milliseconds")
+	watchCmd.Flags().Float64Var(&cpuThreshold, "cpu-threshold", config.DefaultCPUThreshold, "CPU usage threshold percentage, in the scale chosen by --cpu-normalize")
+	watchCmd.Flags().StringVar(&cpuNormalize, "cpu-normalize", "cores", "CPU usage scale: cores (100% per core) or machine (100% is every core busy)")
+	watchCmd.Flags().Float64Var(&cpuSmoothing, "cpu-smoothing", 0, "Weight (alpha, 0-1) of each poll in a moving average of CPU usage, e.g. 0.3 (0 disables)")
+	watchCmd.Flags().StringVar(&cpuValue, "cpu-value", "", "CPU figure alerts and the dashboard use: instant or smoothed (default smoothed when --cpu-smoothing is set)")
+	watchCmd.Flags().StringVar(&heapThreshold, "heap-threshold", "", "Heap usage percentage that warns, optionally followed by the critical one, e.g. 80,95")
+	watchCmd.Flags().StringVar(&lagThreshold, "lag-threshold", "", "Event loop lag in ms that warns, optionally followed by the critical one, e.g. 5,20")
+	watchCmd.Flags().StringVar(&utilizationThreshold, "utilization-threshold", "", "Event loop utilization percentage that warns, optionally followed by the critical one, e.g. 70,90")
+	watchCmd.Flags().StringVar(&gcThreshold, "gc-threshold", "", "GC duration in ms that warns, optionally followed by the critical one, e.g. 10,50")
+	watchCmd.Flags().StringVar(&handlesThreshold, "handles-threshold", "", "Active handle count that warns, optionally followed by the critical one, e.g. 50,100")
+	watchCmd.Flags().IntVar(&pollingMs, "polling-ms", int(config.DefaultPollingInterval/time.Millisecond), "Polling interval in milliseconds")
+	watchCmd.Flags().IntVar(&historySize, "history-size", config.DefaultHistorySize, "Number of polls the event loop lag mean, min, max and p95 are computed over")
+	watchCmd.Flags().IntVar(&retainSamples, "retain-samples", config.DefaultRetainSamples, "Most samples kept in memory for --api-addr history queries")
+	watchCmd.Flags().DurationVar(&retainFor, "retain-for", config.DefaultRetainFor, "How long samples are kept in memory for --api-addr history queries")
 	watchCmd.Flags().IntVar(&inspectPort, "inspect-port", 9229, "V8 inspector port")
+	watchCmd.Flags().StringVar(&inspectHost, "inspect-host", "localhost", "V8 inspector host, or a ws:// or wss:// URL with optional user:password for basic auth")
+	watchCmd.Flags().DurationVar(&inspectTimeout, "inspect-timeout", 2*time.Second, "Timeout for each V8 inspector request")
+	watchCmd.Flags().IntVar(&inspectRetries, "inspect-retries", 2, "Retries for inspector discovery and dropped inspector sessions")
+	watchCmd.Flags().BoolVar(&pollAlign, "poll-align", false, "Align samples to wall-clock multiples of the polling interval")
+	watchCmd.Flags().Float64Var(&pollingJitter, "polling-jitter", 0, "Randomize each polling interval by up to this percentage either way (0 disables)")
+	watchCmd.Flags().BoolVar(&exitOnRecovery, "exit-on-recovery", false, "Exit 0 once no alert has fired for --recovery-period")
+	watchCmd.Flags().DurationVar(&recoveryPeriod, "recovery-period", config.DefaultRecoveryPeriod, "Alert-free period required by --exit-on-recovery")
+	watchCmd.Flags().StringArrayVar(&groupIntervals, "group-interval", nil, "Poll a metric group on its own interval, e.g. v8=2s (repeatable)")
+	watchCmd.Flags().StringSliceVar(&collectGroups, "collect", nil, "Collect only these metric groups, e.g. cpu,memory,eventloop (default all; groups as for --group-interval)")
+	watchCmd.Flags().StringVar(&shmFile, "shm-file", "", "Publish the latest status to a memory-mapped file for local readers")
+	watchCmd.Flags().StringVar(&metricsFile, "openmetrics-file", "", "Atomically rewrite this file with the latest metrics in the Prometheus text format every poll")
+	watchCmd.Flags().StringVar(&jsonlFile, "jsonl", "", "Append every status as a JSON line to this file")
+	watchCmd.Flags().StringVar(&csvFile, "csv", "", "Append the scalar metrics of every poll as a CSV row to this file")
+	watchCmd.Flags().StringVar(&alertDBFile, "alert-db", "", "Record every alert raised or resolved in this SQLite file, for the alerts command")
+	watchCmd.Flags().StringVar(&rotateSize, "log-rotate-size", "", "Rotate --jsonl output once it reaches this size, e.g. 100MB")
+	watchCmd.Flags().IntVar(&rotateKeep, "log-rotate-keep", 5, "Number of rotated --jsonl files to keep")
+	watchCmd.Flags().BoolVar(&rotateCompress, "log-rotate-compress", false, "Gzip rotated --jsonl files")
+	watchCmd.Flags().StringVar(&influxURL, "influx-url", "", "Write every status to this InfluxDB v2 server, e.g. http://localhost:8086")
+	watchCmd.Flags().StringVar(&influxToken, "influx-token", "", "API token for --influx-url")
+	watchCmd.Flags().StringVar(&influxBucket, "influx-bucket", "", "Bucket --influx-url writes to")
+	watchCmd.Flags().StringVar(&influxOrg, "influx-org", "", "Organization of --influx-bucket")
+	watchCmd.Flags().Float64Var(&redrawEpsilon, "redraw-epsilon", 0, "Skip dashboard redraws while metrics change by less than this fraction (0 redraws every poll)")
+	watchCmd.Flags().DurationVar(&redrawMaxInterval, "redraw-max-interval", 5*time.Second, "Redraw at least this often when --redraw-epsilon is set")
+	watchCmd.Flags().IntVar(&refreshMs, "refresh-ms", 1000, "Repaint the dashboard with the latest sample every this many milliseconds, independently of --polling-ms")
+	watchCmd.Flags().IntVar(&smoothSamples, "smooth-samples", 1, "Average the last N heap and GC samples on the dashboard (1 disables)")
+	watchCmd.Flags().StringVar(&output, "output", "table", "Output format: table (dashboard, or plain lines when redirected), json (one status per line) or influx (InfluxDB line protocol)")
+	watchCmd.Flags().BoolVar(&jsonPretty, "json-pretty", false, "Indent JSON output for reading by hand (requires --output json)")
+	watchCmd.Flags().StringVar(&glyphs, "glyphs", "emoji", "Status indicators on the dashboard: emoji, unicode or ascii")
+	watchCmd.Flags().StringVar(&units, "units", "auto", "Byte sizes on the dashboard: si (MB), iec (MiB) or auto (scaled binary units)")
+	watchCmd.Flags().StringVar(&colorMode, "color", "auto", "Color the dashboard: auto (terminals without NO_COLOR), always or never")
+	watchCmd.Flags().StringVar(&theme, "theme", "dark", "Dashboard palette: dark or light for light terminal backgrounds")
+	watchCmd.Flags().BoolVar(&tui, "tui", false, "Show a full-screen TUI instead of the dashboard: space pauses, Tab switches process, arrows scroll alerts, q quits")
+	watchCmd.Flags().BoolVar(&noSparkline, "no-sparkline", false, "Leave out the dashboard's trend column, for terminals without block characters")
+	watchCmd.Flags().StringVar(&gcSource, "gc-source", "perfhooks", "Where GC data comes from: perfhooks (PerformanceObserver) or trace (V8 trace events, adds heap sizes)")
+	watchCmd.Flags().BoolVar(&compareRuntime, "compare-runtime", false, "Sample V8 deoptimized frames and JIT code size via the inspector")
+	watchCmd.Flags().Float64Var(&deoptThreshold, "deopt-threshold", config.DefaultDeoptRateThreshold, "Deoptimized frames per second before alerting (with --compare-runtime)")
+	watchCmd.Flags().Float64Var(&gcReclaimThreshold, "gc-reclaim-threshold", 0.1, "Fraction of heap a GC must free to not count toward memory pressure")
+	watchCmd.Flags().IntVar(&gcReclaimCount, "gc-reclaim-count", config.DefaultGCReclaimCount, "Consecutive low-reclaim GC samples before alerting on memory pressure")
+	watchCmd.Flags().Float64Var(&gcRateThreshold, "gc-rate-threshold", 0, "GC collections per second that raise a GC thrashing alert, critical at twice the rate (0 disables)")
+	watchCmd.Flags().DurationVar(&gcRateWindow, "gc-rate-window", config.DefaultGCRateWindow, "Window the GC collection rate is measured over")
+	watchCmd.Flags().StringArrayVar(&trackConstructors, "track-constructor", nil, "Track instance count and retained size of a constructor via heap snapshots (repeatable)")
+	watchCmd.Flags().DurationVar(&trackInterval, "track-interval", config.DefaultTrackInterval, "Interval between heap snapshots for --track-constructor")
+	watchCmd.Flags().Float64Var(&trackGrowth, "track-growth", config.DefaultTrackGrowth, "Retained size growth percentage over the first snapshot before alerting")
+	watchCmd.Flags().StringArrayVar(&customMetrics, "custom-metric", nil, "Custom metric as name=<JavaScript expression> evaluated in the target each poll (repeatable)")
+	watchCmd.Flags().StringVar(&stuckMetric, "stuck-metric", "", "Custom counter metric whose flatline (with nominal CPU/lag) reports the service as stuck")
+	watchCmd.Flags().DurationVar(&stuckWindow, "stuck-window", config.DefaultStuckWindow, "How long the --stuck-metric counter must stay flat before alerting")
+	watchCmd.Flags().DurationVar(&warmup, "warmup", 0, "Ramp thresholds down from --warmup-factor times their value over this period after start")
+	watchCmd.Flags().Float64Var(&warmupFactor, "warmup-factor", config.DefaultWarmupFactor, "Threshold multiplier at the start of --warmup")
+	watchCmd.Flags().DurationVar(&summaryEvery, "summary-every", 0, "Write a min/mean/max summary of key metrics to stderr at this interval, e.g. 1m")
+	watchCmd.Flags().IntVar(&maxFailures, "max-consecutive-failures", 0, "Exit nonzero (or run --on-failure-cmd) after this many failed polls in a row (0 disables)")
+	watchCmd.Flags().StringVar(&onFailureCmd, "on-failure-cmd", "", "Shell command run instead of exiting when --max-consecutive-failures is reached")
+	watchCmd.Flags().DurationVar(&resolveAfter, "resolve-after", 0, "How long an alert must stay below its threshold before it resolves (0 resolves on the first clear poll)")
+	watchCmd.Flags().IntVar(&alertConsecutive, "alert-consecutive", 1, "Polls in a row an alert must breach before it fires, and stay clear before it resolves")
+	watchCmd.Flags().IntVar(&alertHistory, "alert-history", 10, "Number of recent fired and resolved alerts shown on the dashboard (0 hides them)")
+	watchCmd.Flags().IntVar(&restartLimit, "restart-limit", 3, "Raise a crash loop alert after more than this many restarts within --restart-window (0 disables)")
+	watchCmd.Flags().DurationVar(&restartWindow, "restart-window", config.DefaultRestartWindow, "Window in which restarts are counted for --restart-limit")
+	watchCmd.Flags().StringArrayVar(&relativeThresholds, "relative", nil, "Alert relative to the trailing median, e.g. cpu=2 for twice the baseline (repeatable)")
+	watchCmd.Flags().DurationVar(&baselineWindow, "baseline-window", config.DefaultBaselineWindow, "Trailing window for --relative baselines and --anomaly statistics")
+	watchCmd.Flags().StringSliceVar(&anomalyMetrics, "anomaly", nil, "Also alert when these metrics rise more than --anomaly-k standard deviations above their trailing mean, e.g. cpu,lag")
+	watchCmd.Flags().Float64Var(&anomalyK, "anomaly-k", config.DefaultAnomalyStddevs, "Standard deviations above the trailing mean that count as an --anomaly")
+	watchCmd.Flags().Float64Var(&fdWarnRatio, "fd-warn-ratio", config.DefaultFDWarnRatio, "Fraction of the open file limit (RLIMIT_NOFILE) that raises a warning")
+	watchCmd.Flags().Float64Var(&fdCriticalRatio, "fd-critical-ratio", config.DefaultFDCriticalRatio, "Fraction of the open file limit (RLIMIT_NOFILE) that raises a critical alert")
+	watchCmd.Flags().BoolVar(&k8sEvents, "k8s-events", false, "Publish critical alerts as Kubernetes Events on this pod (in-cluster only)")
+	watchCmd.Flags().BoolVar(&once, "once", false, "Collect a single sample, print it and exit")
+	watchCmd.Flags().StringSliceVar(&requirements, "require", nil, "With --once, fail unless each assertion holds, e.g. 'eventloop.p95<5,memory.rss<200MB'")
+	watchCmd.Flags().DurationVar(&watchDuration, "duration", 0, "Stop after this long and print min/avg/max/p95 of each metric over the run, e.g. 60s")
+	watchCmd.Flags().BoolVar(&detach, "detach", false, "Run the watcher in the background (stop it with the stop command)")
+	watchCmd.Flags().StringVar(&pidFile, "pidfile", defaultPidFile(), "Pidfile of the watcher started with --detach")
+	watchCmd.Flags().StringVar(&detachLog, "log-file", defaultDetachLog(), "File receiving the output of the watcher started with --detach")
+	watchCmd.Flags().BoolVar(&bell, "bell", false, "Ring the terminal bell when an alert is raised (three times for critical)")
+	watchCmd.Flags().BoolVar(&desktop, "desktop-notify", false, "Show a desktop notification when an alert is raised (Linux and macOS)")
+	watchCmd.Flags().StringVar(&k8sEventsMinSeverity, "k8s-events-min-severity", "critical", "Lowest alert severity published by --k8s-events")
+	watchCmd.Flags().StringVar(&bellMinSeverity, "bell-min-severity", "info", "Lowest alert severity that triggers --bell and --desktop-notify")
+	watchCmd.Flags().StringVar(&slackWebhook, "slack-webhook", "", "Post alerts to this Slack incoming webhook URL")
+	watchCmd.Flags().StringVar(&slackMinSeverity, "slack-min-severity", "warning", "Lowest alert severity posted by --slack-webhook")
+	watchCmd.Flags().StringVar(&alertWebhook, "alert-webhook", "", "POST raised and escalated alerts as JSON to this URL")
+	watchCmd.Flags().StringVar(&alertWebhookContentType, "alert-webhook-content-type", "application/json", "Content-Type header of --alert-webhook requests")
+	watchCmd.Flags().StringVar(&alertWebhookToken, "alert-webhook-token", "", "Bearer token sent with --alert-webhook requests")
+	watchCmd.Flags().StringVar(&pagerDutyRoutingKey, "pagerduty-routing-key", "", "Trigger PagerDuty incidents through this Events API v2 integration key")
+	watchCmd.Flags().StringVar(&pagerDutyMinSeverity, "pagerduty-min-severity", "critical", "Lowest alert severity that triggers a --pagerduty-routing-key incident")
+	watchCmd.Flags().DurationVar(&notifyCooldown, "notify-cooldown", 0, "Notify each destination of an alert type at most once per this period unless it escalates, e.g. 15m (0 disables)")
+	watchCmd.Flags().StringVar(&apiAddr, "api-addr", "", "Serve the latest status as JSON over HTTP on this address, e.g. :9100")
+	watchCmd.Flags().StringVar(&socketPath, "socket", "", "Accept commands such as status and annotate on this Unix domain socket (the default path when given without one)")
+	watchCmd.Flags().Lookup("socket").NoOptDefVal = defaultSocketPath()
+	watchCmd.Flags().BoolVar(&captureOnCritical, "capture-on-critical", false, "Capture diagnostics when a critical alert fires, named after the alert's incident ID")
+	watchCmd.Flags().StringSliceVar(&captureTypes, "capture-types", config.DefaultCaptureTypes, "Diagnostics to capture on critical alerts (report, cpu, heap)")
+	watchCmd.Flags().StringVar(&captureDir, "capture-dir", ".", "Directory for diagnostics captured on critical alerts")
+	watchCmd.Flags().DurationVar(&captureDuration, "capture-duration", config.DefaultCaptureDuration, "Length of the CPU profile captured on critical alerts")
+	watchCmd.Flags().BoolVar(&collectNetwork, "network", false, "Collect network throughput of the process (Linux only)")
+	watchCmd.Flags().Float64Var(&networkThreshold, "net-threshold", 0, "Alert when RX+TX throughput in MB/s stays above this (0 disables)")
+	watchCmd.Flags().DurationVar(&networkSustain, "net-sustain", 30*time.Second, "How long throughput must stay above --net-threshold before alerting")
+	watchCmd.Flags().DurationVar(&leakWindow, "leak-window", 0, "Alert on a steady heap growth trend over this window, e.g. 10m (0 disables)")
+	watchCmd.Flags().StringVar(&leakSlope, "leak-slope", "1MB", "Heap growth per minute above which a --leak-window trend is reported")
+	watchCmd.Flags().StringArrayVar(&severityBands, "severity-band", nil, "Custom severity bands for a metric, e.g. memory=warning:150,critical:200,emergency:240 (repeatable)")
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
 	cfg := &config.ServiceConfig{
 		Host:            host,
 		Port:            port,
+		BindAddr:        bindAddr,
+		PortMismatch:    portMismatch,
 		PID:             pid,
+		ProcessName:     processName,
+		PIDFile:         targetPidFile,
+		Container:       containerID,
+		WaitForProcess:  waitProcess,
 		InspectPort:     inspectPort,
+		InspectHost:     inspectHost,
+		InspectTimeout:  inspectTimeout,
+		InspectRetries:  inspectRetries,
 		HeapLimit:       heapLimit,
 		CPUThreshold:    cpuThreshold,
+		CPUNormalize:    cpuNormalize,
+		CPUSmoothing:    cpuSmoothing,
+		CPUValue:        cpuValue,
 		PollingInterval: time.Duration(pollingMs) * time.Millisecond,
+		HistorySize:     historySize,
+		RetainSamples:   retainSamples,
+		RetainFor:       retainFor,
+		PollAlign:       pollAlign,
+		PollingJitter:   pollingJitter,
+		ExitOnRecovery:  exitOnRecovery,
+		RecoveryPeriod:  recoveryPeriod,
+		Collect:         collectGroups,
+
+		SharedMemoryPath: shmFile,
+		OpenMetricsPath:  metricsFile,
+
+		JSONLPath:     jsonlFile,
+		CSVPath:       csvFile,
+		AlertDBPath:   alertDBFile,
+		LogRotateKeep: rotateKeep,
+		LogCompress:   rotateCompress,
+
+		InfluxURL:    influxURL,
+		InfluxToken:  influxToken,
+		InfluxBucket: influxBucket,
+		InfluxOrg:    influxOrg,
+
+		RedrawEpsilon:     redrawEpsilon,
+		RedrawMaxInterval: redrawMaxInterval,
+		RefreshInterval:   time.Duration(refreshMs) * time.Millisecond,
+		SmoothSamples:     smoothSamples,
+		Output:            output,
+		JSONPretty:        jsonPretty,
+		Glyphs:            glyphs,
+		Units:             units,
+		Color:             colorMode,
+		Theme:             theme,
+		NoSparkline:       noSparkline,
+		TUI:               tui,
+
+		GCSource:           gcSource,
+		CompareRuntime:     compareRuntime,
+		DeoptRateThreshold: deoptThreshold,
+
+		GCReclaimThreshold: gcReclaimThreshold,
+		GCReclaimCount:     gcReclaimCount,
+		GCRateThreshold:    gcRateThreshold,
+		GCRateWindow:       gcRateWindow,
+
+		TrackConstructors:    trackConstructors,
+		TrackInterval:        trackInterval,
+		TrackGrowthThreshold: trackGrowth,
+
+		StuckMetric: stuckMetric,
+		StuckWindow: stuckWindow,
+
+		Warmup:       warmup,
+		WarmupFactor: warmupFactor,
+
+		AlertHistory:     alertHistory,
+		ResolveAfter:     resolveAfter,
+		AlertConsecutive: alertConsecutive,
+
+		SummaryEvery: summaryEvery,
+
+		MaxConsecutiveFailures: maxFailures,
+		OnFailureCmd:           onFailureCmd,
+
+		RestartLimit:  restartLimit,
+		RestartWindow: restartWindow,
+
+		FDWarnRatio:     fdWarnRatio,
+		FDCriticalRatio: fdCriticalRatio,
+
+		K8sEvents:     k8sEvents,
+		Bell:          bell,
+		DesktopNotify: desktop,
+
+		K8sEventsMinSeverity: types.AlertSeverity(strings.ToLower(k8sEventsMinSeverity)),
+		BellMinSeverity:      types.AlertSeverity(strings.ToLower(bellMinSeverity)),
+
+		SlackWebhook:     slackWebhook,
+		SlackMinSeverity: types.AlertSeverity(strings.ToLower(slackMinSeverity)),
+
+		AlertWebhook:            alertWebhook,
+		AlertWebhookContentType: alertWebhookContentType,
+		AlertWebhookToken:       alertWebhookToken,
+
+		PagerDutyRoutingKey:  pagerDutyRoutingKey,
+		PagerDutyMinSeverity: types.AlertSeverity(strings.ToLower(pagerDutyMinSeverity)),
+
+		NotifyCooldown: notifyCooldown,
+
+		CaptureOnCritical: captureOnCritical,
+		CaptureTypes:      captureTypes,
+		CaptureDir:        captureDir,
+		CaptureDuration:   captureDuration,
+
+		BaselineWindow: baselineWindow,
+		Anomaly:        anomalyMetrics,
+		AnomalyK:       anomalyK,
+
+		CollectNetwork:   collectNetwork,
+		NetworkThreshold: networkThreshold,
+		NetworkSustain:   networkSustain,
+
+		LeakWindow: leakWindow,
+		LeakSlope:  leakSlope,
+	}
+
+	if rotateSize != "" {
+		size, err := config.ParseSize(rotateSize)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		cfg.LogRotateSize = size
+	}
+
+	for _, spec := range customMetrics {
+		name, expression, err := config.ParseCustomMetric(spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if cfg.CustomMetrics == nil {
+			cfg.CustomMetrics = make(map[string]string)
+		}
+		cfg.CustomMetrics[name] = expression
+	}
+
+	for _, flag := range []struct {
+		spec      string
+		threshold *config.Threshold
+	}{
+		{heapThreshold, &cfg.Thresholds.Heap},
+		{lagThreshold, &cfg.Thresholds.Lag},
+		{utilizationThreshold, &cfg.Thresholds.Utilization},
+		{gcThreshold, &cfg.Thresholds.GC},
+		{handlesThreshold, &cfg.Thresholds.Handles},
+	} {
+		if flag.spec == "" {
+			continue
+		}
+		threshold, err := config.ParseThreshold(flag.spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		*flag.threshold = threshold
+	}
+
+	for _, spec := range severityBands {
+		metric, bands, err := config.ParseSeverityBands(spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if cfg.Bands == nil {
+			cfg.Bands = make(map[string][]config.SeverityBand)
+		}
+		cfg.Bands[metric] = bands
+	}
+
+	for _, spec := range groupIntervals {
+		group, interval, err := config.ParseGroupInterval(spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if cfg.GroupIntervals == nil {
+			cfg.GroupIntervals = make(map[string]time.Duration)
+		}
+		cfg.GroupIntervals[group] = interval
+	}
+
+	for _, spec := range relativeThresholds {
+		metric, factor, err := config.ParseRelativeThreshold(spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		if cfg.Relative == nil {
+			cfg.Relative = make(map[string]float64)
+		}
+		cfg.Relative[metric] = factor
+	}
+
+	var reqs []config.Requirement
+	for _, spec := range requirements {
+		req, err := config.ParseRequirement(spec)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+	if watchAll {
+		if processName == "" {
+			return fmt.Errorf("invalid configuration: --all requires --name")
+		}
+		for _, name := range singleTargetFlags {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("invalid configuration: --%s cannot be combined with --all", name)
+			}
+		}
+		if output != config.OutputTable {
+			return fmt.Errorf("invalid configuration: --all only supports --output table")
+		}
+	}
+	if tui {
+		if once || detach {
+			return fmt.Errorf("invalid configuration: --tui cannot be combined with --once or --detach")
+		}
+		if !display.IsTerminal(os.Stdout) || !display.IsTerminal(os.Stdin) {
+			return fmt.Errorf("invalid configuration: --tui needs a terminal")
+		}
+	}
+	if len(reqs) > 0 && !once {
+		return fmt.Errorf("invalid configuration: --require is only checked with --once")
+	}
+	if watchDuration < 0 {
+		return fmt.Errorf("invalid configuration: duration cannot be negative")
+	}
+	if watchDuration > 0 && once {
+		return fmt.Errorf("invalid configuration: --duration cannot be combined with --once")
+	}
+	// The summary is read back from the sample store, so it must keep the
+	// whole run unless told otherwise
+	if watchDuration > cfg.RetainFor && !cmd.Flags().Changed("retain-for") {
+		cfg.RetainFor = watchDuration
 	}
 
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// An unreadable pidfile is reported at once unless told to wait for the
+	// process; one naming a process that is not running is waited for
+	if cfg.PIDFile != "" && !cfg.WaitForProcess {
+		if _, err := config.ReadPidFile(cfg.PIDFile); err != nil {
+			return err
+		}
+	}
+
+	// Likewise a container that cannot be resolved, e.g. when the runtime
+	// socket is not accessible
+	if cfg.Container != "" && !cfg.WaitForProcess {
+		if _, err := metrics.NewCollector(cfg).FindContainer(cfg.Container, cfg.InspectPort); err != nil {
+			return fmt.Errorf("failed to find container: %w", err)
+		}
+	}
+
+	if once {
+		cmd.SilenceUsage = true
+		return runOnce(cfg, reqs)
+	}
+
+	if detach {
+		return detachWatch(pidFile, detachLog)
+	}
+	if os.Getenv(detachedEnv) != "" {
+		defer removeOwnPidFile(pidFile)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -68,13 +586,108 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	monitor := monitor.New(cfg)
-	
 	go func() {
 		<-sigChan
-		fmt.Println("\nShutting down gracefully...")
+		fmt.Fprintln(os.Stderr, "\nShutting down gracefully...")
 		cancel()
 	}()
 
-	return monitor.Start(ctx)
+	if watchAll {
+		return runWatchAll(ctx, cfg)
+	}
+
+	monitor := monitor.New(cfg)
+
+	if apiAddr != "" {
+		listener, err := net.Listen("tcp", apiAddr)
+		if err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+
+		server := api.NewServer(monitor.Samples(), monitor)
+		httpServer := &http.Server{Handler: server.Handler()}
+		go func() {
+			if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				log.Printf("Warning: API server stopped: %v", err)
+			}
+		}()
+		defer httpServer.Close()
+	}
+
+	if socketPath != "" {
+		socket, err := api.ListenSocket(socketPath)
+		if err != nil {
+			return fmt.Errorf("failed to open control socket: %w", err)
+		}
+		socket.Handle("annotate", func(text string) (interface{}, error) {
+			if text == "" {
+				return nil, fmt.Errorf("annotation text is required")
+			}
+			return monitor.Annotate(text), nil
+		})
+		socket.Handle("status", func(string) (interface{}, error) {
+			status := monitor.Latest()
+			if status == nil {
+				return nil, fmt.Errorf("no sample collected yet")
+			}
+			return status, nil
+		})
+		go func() {
+			if err := socket.Serve(); err != nil {
+				log.Printf("Warning: Control socket stopped: %v", err)
+			}
+		}()
+		defer socket.Close()
+	}
+
+	started := time.Now()
+	if watchDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, watchDuration)
+		defer cancel()
+	}
+
+	// Quitting the TUI stops the watch like Ctrl+C. The terminal is
+	// restored before anything else is printed
+	var tuiDone chan struct{}
+	if tui := monitor.TUI(); tui != nil {
+		tuiDone = make(chan struct{})
+		go func() {
+			defer close(tuiDone)
+			if err := tui.Run(ctx); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			cancel()
+		}()
+	}
+	err := monitor.Start(ctx)
+	stopped := ctx.Err() != nil
+	if tuiDone != nil {
+		cancel()
+		<-tuiDone
+	}
+	if err != nil {
+		return err
+	}
+
+	// Stopped by a signal: the report goes below the last dashboard frame,
+	// or to stderr to keep JSON output parseable. A --duration run prints
+	// its run summary instead, so only one summary ends the session
+	if stopped && watchDuration == 0 {
+		out := os.Stdout
+		if cfg.Output == config.OutputJSON || cfg.Output == config.OutputInflux {
+			out = os.Stderr
+		}
+		units, _ := display.LookupUnits(cfg.Units)
+		display.PrintSummary(out, monitor.Session(), units)
+	}
+	if watchDuration > 0 {
+		// JSON output ends with the summary object; a table would break
+		// line protocol
+		out := os.Stdout
+		if cfg.Output == config.OutputInflux {
+			out = os.Stderr
+		}
+		return printRunSummary(out, monitor.Samples(), started, cfg)
+	}
+	return nil
 }
\ No newline at end of file
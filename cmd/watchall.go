@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"stackpulse/internal/config"
+	"stackpulse/internal/display"
+	"stackpulse/internal/metrics"
+	"stackpulse/internal/monitor"
+	"stackpulse/internal/store"
+	"stackpulse/internal/types"
+)
+
+// Flags that write or serve the output of a single monitor, which --all
+// has no one monitor for
+var singleTargetFlags = []string{
+	"once", "duration", "detach", "api-addr", "socket",
+	"shm-file", "openmetrics-file", "jsonl", "csv", "influx-url",
+	"inspect-port", "inspect-host",
+}
+
+// targetExit is the end of one process's monitor in --all mode.
+type targetExit struct {
+	pid int
+	err error
+}
+
+// runWatchAll monitors every process matching cfg.ProcessName (--all) with
+// a headless monitor each, shown on the fleet dashboard of the aggregate
+// command. Each process is reached on the inspector port of its own command
+// line; one started without --inspect gets system metrics only. With
+// cfg.TUI the processes are tabs of the TUI instead. It returns when ctx is
+// done, every monitored process has exited or the TUI is quit.
+func runWatchAll(ctx context.Context, cfg *config.ServiceConfig) error {
+	collector := metrics.NewCollector(cfg)
+	pids, err := collector.FindProcessByName(cfg.ProcessName)
+	if err != nil {
+		return fmt.Errorf("failed to find process: %w", err)
+	}
+	sort.Ints(pids)
+
+	// The monitors share one connection to the alert database
+	var alertDB *store.AlertDB
+	if cfg.AlertDBPath != "" {
+		alertDB, err = store.OpenAlertDB(cfg.AlertDBPath)
+		if err != nil {
+			return fmt.Errorf("failed to open alert database: %w", err)
+		}
+		defer alertDB.Close()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Quitting the TUI stops every monitor, and the terminal is restored
+	// before returning
+	var tui *display.TUI
+	if cfg.TUI {
+		tui = display.NewTUI(monitor.NewDashboard(cfg))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := tui.Run(ctx); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			cancel()
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
+	updates := make(chan types.Status, 16*len(pids))
+	exits := make(chan targetExit, len(pids))
+	entries := make([]display.FleetEntry, len(pids))
+	index := make(map[int]int, len(pids))
+	for i, pid := range pids {
+		target := *cfg
+		target.ProcessName = ""
+		target.PID = pid
+		if port, ok := collector.InspectorPort(pid); ok {
+			target.InspectPort = port
+		} else {
+			// Nothing listens on port 0, so inspector groups fail at once
+			log.Printf("Warning: Process %d was started without --inspect; collecting system metrics only", pid)
+			target.InspectPort, target.InspectRetries = 0, 0
+		}
+
+		// Matches usually share a name, so the PID tells them apart
+		name := fmt.Sprintf("pid:%d", pid)
+		if proc, err := process.NewProcess(int32(pid)); err == nil {
+			if n, err := proc.Name(); err == nil && n != "" {
+				name = fmt.Sprintf("%s:%d", n, pid)
+			}
+		}
+		entries[i] = display.FleetEntry{Name: name}
+		index[pid] = i
+
+		mon := monitor.NewHeadless(&target)
+		mon.Subscribe(updates)
+		mon.RecordAlerts(alertDB)
+		go func(pid int) {
+			exits <- targetExit{pid: pid, err: mon.Start(ctx)}
+		}(pid)
+	}
+
+	dashboard := display.NewFleetDashboard()
+	if glyphs, ok := display.LookupGlyphs(cfg.Glyphs); ok {
+		dashboard.SetGlyphs(glyphs)
+	}
+	if units, ok := display.LookupUnits(cfg.Units); ok {
+		dashboard.SetUnits(units)
+	}
+	if mode, ok := display.LookupColorMode(cfg.Color); ok {
+		display.SetColorMode(mode)
+	}
+	if theme, ok := display.LookupTheme(cfg.Theme); ok {
+		dashboard.SetTheme(theme)
+	}
+
+	ticker := time.NewTicker(max(cfg.PollingInterval, cfg.RefreshInterval))
+	defer ticker.Stop()
+
+	var failed []error
+	for running := len(pids); running > 0; {
+		select {
+		case status := <-updates:
+			entry := &entries[index[status.PID]]
+			entry.Status = &status
+			entry.LastSeen = status.Timestamp
+			entry.Err = nil
+			if tui != nil {
+				tui.Update(&status)
+			}
+		case exit := <-exits:
+			running--
+			// A monitor ends without an error when its process exits
+			entry := &entries[index[exit.pid]]
+			entry.Err = exit.err
+			if exit.err != nil {
+				failed = append(failed, fmt.Errorf("process %d: %w", exit.pid, exit.err))
+			} else if ctx.Err() == nil {
+				entry.Err = fmt.Errorf("process %d exited", exit.pid)
+			}
+			if tui != nil {
+				tui.SetDown(exit.pid, exit.err)
+			}
+		case <-ticker.C:
+			if tui != nil {
+				continue
+			}
+			var alerts []types.Alert
+			for _, entry := range entries {
+				if entry.Status != nil && entry.Err == nil {
+					alerts = append(alerts, entry.Status.Alerts...)
+				}
+			}
+			dashboard.Update(entries, alerts)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Join(failed...)
+}
@@ -0,0 +1,155 @@
+// Package aggregate polls remote StackPulse instances for a fleet view.
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"stackpulse/internal/types"
+)
+
+// Target is one remote StackPulse instance serving its status over HTTP
+// (watch --api-addr).
+type Target struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+}
+
+// TargetState is the latest poll result of a target. Status keeps the last
+// good snapshot when a poll fails.
+type TargetState struct {
+	Target   Target
+	Status   *types.Status
+	Err      error
+	LastSeen time.Time
+}
+
+// LoadTargets reads a targets file of the form:
+//
+//	targets:
+//	  - name: api
+//	    url: http://10.0.0.5:9100
+func LoadTargets(path string) ([]Target, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	var file struct {
+		Targets []Target `mapstructure:"targets"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse targets file: %w", err)
+	}
+	if len(file.Targets) == 0 {
+		return nil, fmt.Errorf("no targets defined in %s", path)
+	}
+
+	for i, t := range file.Targets {
+		if t.URL == "" {
+			return nil, fmt.Errorf("target %d has no url", i+1)
+		}
+		if t.Name == "" {
+			file.Targets[i].Name = t.URL
+		}
+		file.Targets[i].URL = strings.TrimSuffix(t.URL, "/")
+	}
+	return file.Targets, nil
+}
+
+// Aggregator polls every target concurrently and keeps its latest state.
+type Aggregator struct {
+	targets []Target
+	client  *http.Client
+	states  []TargetState
+}
+
+func New(targets []Target, timeout time.Duration) *Aggregator {
+	states := make([]TargetState, len(targets))
+	for i, t := range targets {
+		states[i].Target = t
+	}
+	return &Aggregator{
+		targets: targets,
+		client:  &http.Client{Timeout: timeout},
+		states:  states,
+	}
+}
+
+// Poll fetches every target once and returns the updated states, in the
+// order the targets were defined.
+func (a *Aggregator) Poll(ctx context.Context) []TargetState {
+	var wg sync.WaitGroup
+	for i := range a.states {
+		wg.Add(1)
+		go func(state *TargetState) {
+			defer wg.Done()
+			status, err := a.fetch(ctx, state.Target)
+			state.Err = err
+			if err == nil {
+				state.Status = status
+				state.LastSeen = time.Now()
+			}
+		}(&a.states[i])
+	}
+	wg.Wait()
+
+	states := make([]TargetState, len(a.states))
+	copy(states, a.states)
+	return states
+}
+
+func (a *Aggregator) fetch(ctx context.Context, target Target) (*types.Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL+"/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach target: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("target returned %s", resp.Status)
+	}
+
+	var status types.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode target status: %w", err)
+	}
+	return &status, nil
+}
+
+// Alerts flattens the alerts of every target, prefixing each message with
+// the target name, and adds a critical alert for every unreachable target.
+func Alerts(states []TargetState) []types.Alert {
+	var alerts []types.Alert
+	for _, state := range states {
+		if state.Err != nil {
+			alerts = append(alerts, types.Alert{
+				Type:      types.AlertTypeTarget,
+				Severity:  types.SeverityCritical,
+				Message:   fmt.Sprintf("%s: %v", state.Target.Name, state.Err),
+				Timestamp: time.Now(),
+			})
+			continue
+		}
+		if state.Status == nil {
+			continue
+		}
+		for _, alert := range state.Status.Alerts {
+			alert.Message = state.Target.Name + ": " + alert.Message
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
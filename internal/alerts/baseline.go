@@ -0,0 +1,75 @@
+package alerts
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"stackpulse/internal/config"
+	"stackpulse/internal/types"
+)
+
+// Samples needed in the window before a relative threshold or anomaly
+// check is enforced
+const minBaselineSamples = 10
+
+// trailing records value in the metric's history and returns the values of
+// the samples seen in the trailing window before it.
+func (m *Manager) trailing(metric string, value float64, window time.Duration) []float64 {
+	now := m.now()
+	history := m.history[metric]
+	for len(history) > 0 && now.Sub(history[0].at) > window {
+		history = history[1:]
+	}
+
+	values := make([]float64, len(history))
+	for i, s := range history {
+		values[i] = s.value
+	}
+
+	m.history[metric] = append(history, sample{value: value, at: now})
+	return values
+}
+
+// medianOf returns the median of values, sorting them in place.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	mid := values[len(values)/2]
+	if len(values)%2 == 0 {
+		mid = (values[len(values)/2-1] + mid) / 2
+	}
+	return mid
+}
+
+// meanStddev returns the mean and population standard deviation of values.
+func meanStddev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+// thresholdBands turns a configured threshold into its warning and critical
+// bands.
+func thresholdBands(t config.Threshold) []config.SeverityBand {
+	return []config.SeverityBand{
+		{Above: t.Warning, Severity: types.SeverityWarning},
+		{Above: t.Critical, Severity: types.SeverityCritical},
+	}
+}
+
+// relativeBands scales the baseline by factor for a warning and by twice the
+// factor for a critical alert.
+func relativeBands(median, factor float64) []config.SeverityBand {
+	return []config.SeverityBand{
+		{Above: median * factor, Severity: types.SeverityWarning},
+		{Above: median * factor * 2, Severity: types.SeverityCritical},
+	}
+}
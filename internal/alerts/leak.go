@@ -0,0 +1,107 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"stackpulse/internal/config"
+	"stackpulse/internal/types"
+)
+
+// Lowest coefficient of determination (R²) for heap growth to count as a
+// trend rather than the sawtooth of allocation and collection
+const minLeakR2 = 0.8
+
+// checkLeak flags heap used that has grown steadily over the whole leak
+// window: a least-squares line through the samples must rise faster than
+// the leak slope and explain most of their variance. The message projects
+// when the heap would reach V8's heap size limit at that rate.
+func (m *Manager) checkLeak(status *types.Status, cfg *config.ServiceConfig) (types.Alert, bool) {
+	if cfg.LeakWindow <= 0 || status.Memory.HeapUsed == 0 {
+		return types.Alert{}, false
+	}
+
+	// A restarted process starts a new trend
+	if !status.StartedAt.Equal(m.heapStart) {
+		m.heapSamples = nil
+		m.heapStart = status.StartedAt
+	}
+
+	now := m.now()
+	m.heapSamples = append(m.heapSamples, sample{value: float64(status.Memory.HeapUsed), at: now})
+
+	// Keep one sample at or before the window start so the fit spans it
+	for len(m.heapSamples) > 1 && now.Sub(m.heapSamples[1].at) >= cfg.LeakWindow {
+		m.heapSamples = m.heapSamples[1:]
+	}
+	if len(m.heapSamples) < 3 || now.Sub(m.heapSamples[0].at) < cfg.LeakWindow {
+		return types.Alert{}, false
+	}
+
+	slope, r2 := linearFit(m.heapSamples)
+	if r2 < minLeakR2 {
+		return types.Alert{}, false
+	}
+
+	bands, custom := cfg.Bands["leak"]
+	if !custom {
+		threshold, err := cfg.ParseLeakSlope()
+		if err != nil {
+			return types.Alert{}, false
+		}
+		bands = []config.SeverityBand{
+			{Above: threshold, Severity: types.SeverityWarning},
+			{Above: threshold * 2, Severity: types.SeverityCritical},
+		}
+	}
+	band, breached := evaluateBands(slope, bands)
+	if !breached {
+		return types.Alert{}, false
+	}
+
+	message := fmt.Sprintf("Possible memory leak: heap used grew %.2f MB/min over the last %s (R² %.2f, threshold: %.2f MB/min)",
+		slope/1024/1024, cfg.LeakWindow, r2, band.Above/1024/1024)
+	if limit, used := status.V8.HeapSizeLimit, status.Memory.HeapUsed; limit > used {
+		minutes := float64(limit-used) / slope
+		message += fmt.Sprintf(", heap limit of %.0f MB reached in about %s",
+			float64(limit)/1024/1024, time.Duration(minutes*float64(time.Minute)).Round(time.Minute))
+	}
+
+	return types.Alert{
+		Type:      types.AlertTypeMemoryLeak,
+		Severity:  band.Severity,
+		Message:   message,
+		Value:     slope,
+		Threshold: band.Above,
+		Timestamp: now,
+	}, true
+}
+
+// linearFit returns the least-squares slope of samples per minute and the
+// coefficient of determination of the fit. Deviations from the means are
+// summed rather than raw squares, which would lose precision at heap sizes.
+func linearFit(samples []sample) (slope, r2 float64) {
+	n := float64(len(samples))
+	origin := samples[0].at
+
+	var meanX, meanY float64
+	for _, s := range samples {
+		meanX += s.at.Sub(origin).Minutes()
+		meanY += s.value
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxx, sxy, syy float64
+	for _, s := range samples {
+		dx := s.at.Sub(origin).Minutes() - meanX
+		dy := s.value - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, 0
+	}
+	return sxy / sxx, sxy * sxy / (sxx * syy)
+}
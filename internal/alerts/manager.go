@@ -2,153 +2,768 @@ package alerts
 
 import (
 	"fmt"
+	"sort"
 	"time"
 	"stackpulse/internal/config"
 	"stackpulse/internal/types"
 )
 
+// RSS below this fraction of the previous sample counts as a restart
+const rssCollapseRatio = 0.1
+
+// Consecutive timed-out lag measurements before the event loop counts as
+// blocked rather than briefly slow to answer
+const blockedTimeouts = 2
+
+// rule describes how one metric is checked: where its value comes from and
+// the default severity bands it escalates through.
+type rule struct {
+	name      string
+	alertType types.AlertType
+	value     func(status *types.Status, cfg *config.ServiceConfig) (float64, bool)
+	bands     func(cfg *config.ServiceConfig) []config.SeverityBand
+	message   func(status *types.Status, value, threshold float64) string
+}
+
+var rules = []rule{
+	{
+		name:      "cpu",
+		alertType: types.AlertTypeCPU,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return status.CPU.Percent(), !status.CPU.FirstSample
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return []config.SeverityBand{
+				{Above: cfg.CPUThreshold, Severity: types.SeverityWarning},
+				{Above: cfg.CPUCriticalThreshold(), Severity: types.SeverityCritical},
+			}
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High CPU usage: %.2f%% (threshold: %.2f%%)", value, threshold)
+		},
+	},
+	{
+		// Warning above the heap limit, then critical and emergency at 4/3
+		// and 8/5 of it (150, 200 and 240 MB by default)
+		name:      "memory",
+		alertType: types.AlertTypeMemory,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return float64(status.Memory.RSS) / 1024 / 1024, true
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			limit, err := cfg.ParseHeapLimit()
+			if err != nil {
+				limit = config.DefaultHeapLimit
+			}
+			limitMB := float64(limit) / 1024 / 1024
+			return []config.SeverityBand{
+				{Above: limitMB, Severity: types.SeverityWarning},
+				{Above: limitMB * 4 / 3, Severity: types.SeverityCritical},
+				{Above: limitMB * 8 / 5, Severity: types.SeverityEmergency},
+			}
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High memory usage: %.1f MB (threshold: %.0f MB)", value, threshold)
+		},
+	},
+	{
+		name:      "heap",
+		alertType: types.AlertTypeHeap,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return types.HeapUsagePercent(status.Memory)
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return thresholdBands(cfg.AlertThresholds().Heap)
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High heap usage: %.1f%% (threshold: %.0f%%)", value, threshold)
+		},
+	},
+	{
+		name:      "lag",
+		alertType: types.AlertTypeEventLoop,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return status.EventLoop.Lag, true
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return thresholdBands(cfg.AlertThresholds().Lag)
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High event loop lag: %.2fms (threshold: %gms)", value, threshold)
+		},
+	},
+	{
+		name:      "utilization",
+		alertType: types.AlertTypeEventLoop,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return status.EventLoop.Utilization, true
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return thresholdBands(cfg.AlertThresholds().Utilization)
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High event loop utilization: %.1f%% (threshold: %.0f%%)", value, threshold)
+		},
+	},
+	{
+		name:      "gc",
+		alertType: types.AlertTypeGC,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return status.GC.Duration, true
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return thresholdBands(cfg.AlertThresholds().GC)
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("Long GC duration: %.2fms (threshold: %gms)", value, threshold)
+		},
+	},
+	{
+		name:      "handles",
+		alertType: types.AlertTypeHandles,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			// Without the inspector the count is a zero placeholder
+			return float64(status.Handles.Active), status.Sources.Handles
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return thresholdBands(cfg.AlertThresholds().Handles)
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High handle count: %.0f (threshold: %.0f)", value, threshold)
+		},
+	},
+	{
+		name:      "deopt",
+		alertType: types.AlertTypeDeopt,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return status.V8.DeoptRate, cfg.CompareRuntime
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return []config.SeverityBand{
+				{Above: cfg.DeoptRateThreshold, Severity: types.SeverityWarning},
+				{Above: cfg.DeoptRateThreshold * 2, Severity: types.SeverityCritical},
+			}
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("Many deoptimized frames: %.1f/s (threshold: %.1f/s, top reason: %s)", value, threshold, status.V8.TopDeoptReason)
+		},
+	},
+	{
+		name:      "oom",
+		alertType: types.AlertTypeOOM,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			if status.Scheduling == nil || status.Scheduling.OOMScore < 0 {
+				return 0, false
+			}
+			return float64(status.Scheduling.OOMScore), true
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			return []config.SeverityBand{
+				{Above: 800, Severity: types.SeverityWarning},
+				{Above: 900, Severity: types.SeverityCritical},
+			}
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("High OOM score: %.0f (threshold: %.0f, adj: %d), the kernel is likely to kill this process under memory pressure", value, threshold, status.Scheduling.OOMScoreAdj)
+		},
+	},
+	{
+		name:      "fds",
+		alertType: types.AlertTypeDescriptors,
+		value: func(status *types.Status, cfg *config.ServiceConfig) (float64, bool) {
+			return types.FDUsagePercent(status.Handles)
+		},
+		bands: func(cfg *config.ServiceConfig) []config.SeverityBand {
+			warn, critical := cfg.FDRatios()
+			return []config.SeverityBand{
+				{Above: warn * 100, Severity: types.SeverityWarning},
+				{Above: critical * 100, Severity: types.SeverityCritical},
+			}
+		},
+		message: func(status *types.Status, value, threshold float64) string {
+			return fmt.Sprintf("Open file descriptors near limit: %d/%d (%.1f%%, threshold: %.0f%%), opening files and sockets fails with EMFILE at the limit", status.Handles.FDs, status.Handles.FDLimit, value, threshold)
+		},
+	},
+}
+
 type Manager struct {
-	activeAlerts map[string]types.Alert
+	// Open incidents by alert type and PID, and the transitions of the
+	// latest check
+	incidents map[string]*incident
+	events    []types.AlertEvent
+
+	// Consecutive breaching checks of alert types without an open incident,
+	// by the same key, for cfg.AlertConsecutive
+	pending map[string]int
+
+	// First check, from which the warmup ramp is measured
+	started time.Time
+
+	// Consecutive polls whose collections reclaimed too little heap
+	lowReclaimStreak int
+
+	// Samples of the stuck-detection counter within the stuck window
+	throughput []sample
+
+	// Running GC collection totals within the GC rate window
+	gcTotals []sample
+
+	// Trailing samples per metric for relative thresholds and anomalies
+	history map[string][]sample
+
+	// When network throughput last rose above the threshold
+	networkHighSince time.Time
+
+	// Heap used within the leak window, and the start time of the process
+	// they were taken from
+	heapSamples []sample
+	heapStart   time.Time
+
+	// Nice value seen on the first scheduling sample
+	initialNice *int32
+
+	// Process start time and RSS of the previous sample, and when restarts
+	// were seen within the restart window
+	lastStart time.Time
+	lastRSS   uint64
+	restarts  []time.Time
+
+	// Time source for windows and alert timestamps
+	now func() time.Time
+}
+
+// incident is an alert type firing for one process. It stays open while
+// the type keeps firing and for cfg.ResolveAfter after it stops.
+type incident struct {
+	alerts      []types.Alert // from the last check that breached
+	clearSince  time.Time     // zero while breached
+	clearChecks int           // consecutive checks without a breach
+}
+
+type sample struct {
+	value float64
+	at    time.Time
 }
 
 func NewManager() *Manager {
 	return &Manager{
-		activeAlerts: make(map[string]types.Alert),
+		incidents: make(map[string]*incident),
+		pending:   make(map[string]int),
+		history:   make(map[string][]sample),
+		now:       time.Now,
 	}
 }
 
+// SetClock replaces the time source, so recorded statuses can be replayed
+// at the time they were taken.
+func (m *Manager) SetClock(now func() time.Time) {
+	m.now = now
+}
+
 func (m *Manager) CheckThresholds(status *types.Status, cfg *config.ServiceConfig) []types.Alert {
 	var alerts []types.Alert
 
-	// Check CPU threshold
-	if status.CPU.Usage > cfg.CPUThreshold {
-		severity := types.SeverityWarning
-		if status.CPU.Usage > 90 {
-			severity = types.SeverityCritical
-		}
-		
-		alert := types.Alert{
-			Type:      types.AlertTypeCPU,
-			Severity:  severity,
-			Message:   fmt.Sprintf("High CPU usage: %.2f%% (threshold: %.2f%%)", status.CPU.Usage, cfg.CPUThreshold),
-			Value:     status.CPU.Usage,
-			Threshold: cfg.CPUThreshold,
-			Timestamp: time.Now(),
+	// Fixed thresholds are relaxed during warmup; relative ones already
+	// adapt to the process
+	scale := m.warmupScale(cfg, m.now())
+
+	for _, r := range rules {
+		value, ok := r.value(status, cfg)
+		if !ok {
+			continue
 		}
-		alerts = append(alerts, alert)
-	}
 
-	// Check memory threshold (simplified - would parse cfg.HeapLimit in production)
-	memoryMB := float64(status.Memory.RSS) / 1024 / 1024
-	if memoryMB > 150 { // Simplified threshold
-		severity := types.SeverityWarning
-		if memoryMB > 200 {
-			severity = types.SeverityCritical
+		factor, relative := cfg.Relative[r.name]
+		anomaly := cfg.DetectsAnomalies(r.name)
+		var trailing []float64
+		if relative || anomaly {
+			trailing = m.trailing(r.name, value, cfg.BaselineWindow)
 		}
-		
-		alert := types.Alert{
-			Type:      types.AlertTypeMemory,
-			Severity:  severity,
-			Message:   fmt.Sprintf("High memory usage: %.1f MB (threshold: 150 MB)", memoryMB),
-			Value:     memoryMB,
-			Threshold: 150,
-			Timestamp: time.Now(),
+
+		// Anomalies are checked on top of the metric's own thresholds
+		if anomaly && len(trailing) >= minBaselineSamples {
+			if alert, ok := m.checkAnomaly(r, status, value, trailing, cfg.AnomalyStddevs()); ok {
+				alerts = append(alerts, alert)
+			}
 		}
-		alerts = append(alerts, alert)
-	}
 
-	// Check heap usage
-	if status.Memory.HeapTotal > 0 {
-		heapUsage := (float64(status.Memory.HeapUsed) / float64(status.Memory.HeapTotal)) * 100
-		if heapUsage > 80 {
-			severity := types.SeverityWarning
-			if heapUsage > 95 {
-				severity = types.SeverityCritical
+		message := r.message
+		bands, custom := cfg.Bands[r.name]
+		if relative {
+			// Thresholds follow the trailing median instead of fixed values
+			if len(trailing) < minBaselineSamples {
+				continue
+			}
+			median := medianOf(trailing)
+			if median <= 0 {
+				continue
+			}
+			bands = relativeBands(median, factor)
+			message = func(status *types.Status, value, threshold float64) string {
+				return fmt.Sprintf("%s [%.1fx trailing median %.2f]", r.message(status, value, threshold), threshold/median, median)
 			}
-			
-			alert := types.Alert{
-				Type:      types.AlertTypeHeap,
-				Severity:  severity,
-				Message:   fmt.Sprintf("High heap usage: %.1f%% (threshold: 80%%)", heapUsage),
-				Value:     heapUsage,
-				Threshold: 80,
-				Timestamp: time.Now(),
+		} else {
+			if !custom {
+				bands = r.bands(cfg)
 			}
-			alerts = append(alerts, alert)
+			if scale > 1 {
+				bands = scaleBands(bands, scale)
+			}
+		}
+
+		band, breached := evaluateBands(value, bands)
+		if !breached {
+			continue
 		}
+
+		alerts = append(alerts, types.Alert{
+			Type:      r.alertType,
+			Severity:  band.Severity,
+			Message:   message(status, value, band.Above),
+			Value:     value,
+			Threshold: band.Above,
+			Timestamp: m.now(),
+		})
 	}
 
-	// Check event loop lag
-	if status.EventLoop.Lag > 5 {
+	// Check GC reclaim efficiency; only polls that saw a collection count
+	if status.GC.Collections > 0 && status.GC.HeapSizeBefore > 0 {
+		if status.GC.ReclaimEfficiency < cfg.GCReclaimThreshold {
+			m.lowReclaimStreak++
+		} else {
+			m.lowReclaimStreak = 0
+		}
+	}
+	if cfg.GCReclaimCount > 0 && m.lowReclaimStreak >= cfg.GCReclaimCount {
 		severity := types.SeverityWarning
-		if status.EventLoop.Lag > 20 {
+		if m.lowReclaimStreak >= cfg.GCReclaimCount*2 {
 			severity = types.SeverityCritical
 		}
-		
+
 		alert := types.Alert{
-			Type:      types.AlertTypeEventLoop,
+			Type:      types.AlertTypeGCPressure,
 			Severity:  severity,
-			Message:   fmt.Sprintf("High event loop lag: %.2fms (threshold: 5ms)", status.EventLoop.Lag),
-			Value:     status.EventLoop.Lag,
-			Threshold: 5,
-			Timestamp: time.Now(),
+			Message:   fmt.Sprintf("Memory pressure: last %d GC samples reclaimed under %.0f%% of heap (latest: %.1f%%)", m.lowReclaimStreak, cfg.GCReclaimThreshold*100, status.GC.ReclaimEfficiency*100),
+			Value:     status.GC.ReclaimEfficiency,
+			Threshold: cfg.GCReclaimThreshold,
+			Timestamp: m.now(),
 		}
 		alerts = append(alerts, alert)
 	}
 
-	// Check event loop utilization
-	if status.EventLoop.Utilization > 70 {
-		severity := types.SeverityWarning
-		if status.EventLoop.Utilization > 90 {
-			severity = types.SeverityCritical
+	// Check for collections becoming more frequent
+	if alert, ok := m.checkGCRate(status, cfg); ok {
+		alerts = append(alerts, alert)
+	}
+
+	// Check tracked constructors for retained size growth
+	for _, ctor := range status.Constructors {
+		bands, custom := cfg.Bands["constructor"]
+		if !custom {
+			bands = []config.SeverityBand{
+				{Above: cfg.TrackGrowthThreshold, Severity: types.SeverityWarning},
+				{Above: cfg.TrackGrowthThreshold * 2, Severity: types.SeverityCritical},
+			}
 		}
-		
-		alert := types.Alert{
-			Type:      types.AlertTypeEventLoop,
-			Severity:  severity,
-			Message:   fmt.Sprintf("High event loop utilization: %.1f%% (threshold: 70%%)", status.EventLoop.Utilization),
-			Value:     status.EventLoop.Utilization,
-			Threshold: 70,
-			Timestamp: time.Now(),
+
+		band, breached := evaluateBands(ctor.GrowthPercent, bands)
+		if !breached {
+			continue
 		}
+
+		alerts = append(alerts, types.Alert{
+			Type:     types.AlertTypeConstructor,
+			Severity: band.Severity,
+			Message: fmt.Sprintf("%s retained size grew %.1f%% to %.1f MB across %d instances (threshold: %.0f%%)",
+				ctor.Name, ctor.GrowthPercent, float64(ctor.RetainedSize)/1024/1024, ctor.Count, band.Above),
+			Value:     ctor.GrowthPercent,
+			Threshold: band.Above,
+			Timestamp: m.now(),
+		})
+	}
+
+	// Check for sustained high network throughput
+	if alert, ok := m.checkNetwork(status, cfg); ok {
 		alerts = append(alerts, alert)
 	}
 
-	// Check GC duration
-	if status.GC.Duration > 10 {
-		severity := types.SeverityWarning
-		if status.GC.Duration > 50 {
-			severity = types.SeverityCritical
+	// Check for a steadily growing heap
+	if alert, ok := m.checkLeak(status, cfg); ok {
+		alerts = append(alerts, alert)
+	}
+
+	// Check for priority changes since monitoring started
+	if alert, ok := m.checkPriority(status); ok {
+		alerts = append(alerts, alert)
+	}
+
+	// Check for repeated restarts
+	if alert, ok := m.checkRestarts(status, cfg); ok {
+		alerts = append(alerts, alert)
+	}
+
+	// Check for an event loop that stopped answering altogether
+	if alert, ok := m.checkBlocked(status); ok {
+		alerts = append(alerts, alert)
+	}
+
+	// Check for a stuck service: resources look fine but work stopped
+	if alert, ok := m.checkStuck(status, cfg, len(alerts) == 0); ok {
+		alerts = append(alerts, alert)
+	}
+
+	return m.track(status, cfg, alerts)
+}
+
+// track updates the open incidents with the alerts breached by status and
+// returns the active alerts: those of open incidents, plus the last alerts
+// of incidents that have not stayed clear long enough to resolve. An
+// incident fires once its type has breached on cfg.AlertConsecutive checks
+// in a row, and resolves once it has stayed clear for cfg.ResolveAfter and
+// as many checks; both transitions are kept for Events.
+func (m *Manager) track(status *types.Status, cfg *config.ServiceConfig, alerts []types.Alert) []types.Alert {
+	now := m.now()
+	m.events = nil
+
+	breached := make(map[string][]types.Alert)
+	var keys []string
+	for _, alert := range alerts {
+		key := fmt.Sprintf("%s/%d", alert.Type, status.PID)
+		if _, ok := breached[key]; !ok {
+			keys = append(keys, key)
 		}
-		
-		alert := types.Alert{
-			Type:      "gc",
-			Severity:  severity,
-			Message:   fmt.Sprintf("Long GC duration: %.2fms (threshold: 10ms)", status.GC.Duration),
-			Value:     status.GC.Duration,
-			Threshold: 10,
-			Timestamp: time.Now(),
+		breached[key] = append(breached[key], alert)
+	}
+
+	var active []types.Alert
+	for _, key := range keys {
+		group := breached[key]
+		inc, ok := m.incidents[key]
+		if !ok {
+			m.pending[key]++
+			if m.pending[key] < cfg.AlertConsecutive {
+				continue
+			}
+			delete(m.pending, key)
+			inc = &incident{}
+			m.incidents[key] = inc
 		}
-		alerts = append(alerts, alert)
+
+		id := fmt.Sprintf("%s-%s", group[0].Type, group[0].Timestamp.Format("20060102-150405"))
+		if ok {
+			id = inc.alerts[0].IncidentID
+		}
+		for i := range group {
+			group[i].IncidentID = id
+		}
+		if !ok {
+			m.events = append(m.events, types.AlertEvent{Alert: group[0], Timestamp: now})
+		}
+
+		inc.alerts = group
+		inc.clearSince = time.Time{}
+		inc.clearChecks = 0
+		active = append(active, group...)
 	}
 
-	// Check handle count
-	if status.Handles.Active > 50 {
-		severity := types.SeverityWarning
-		if status.Handles.Active > 100 {
-			severity = types.SeverityCritical
+	// A streak toward opening an incident ends with the first clear check
+	for key := range m.pending {
+		if _, ok := breached[key]; !ok {
+			delete(m.pending, key)
 		}
-		
-		alert := types.Alert{
-			Type:      "handles",
-			Severity:  severity,
-			Message:   fmt.Sprintf("High handle count: %d (threshold: 50)", status.Handles.Active),
-			Value:     float64(status.Handles.Active),
-			Threshold: 50,
-			Timestamp: time.Now(),
+	}
+
+	// Incidents that stopped breaching are held until they have stayed clear
+	// for the resolve delay
+	var held []string
+	for key, inc := range m.incidents {
+		if _, ok := breached[key]; ok {
+			continue
 		}
-		alerts = append(alerts, alert)
+		if inc.clearSince.IsZero() {
+			inc.clearSince = now
+		}
+		inc.clearChecks++
+		if now.Sub(inc.clearSince) < cfg.ResolveAfter || inc.clearChecks < cfg.AlertConsecutive {
+			held = append(held, key)
+			continue
+		}
+
+		resolved := inc.alerts[0]
+		resolved.Severity = types.SeverityInfo
+		m.events = append(m.events, types.AlertEvent{Alert: resolved, Resolved: true, Timestamp: now})
+		delete(m.incidents, key)
+	}
+	sort.Strings(held)
+	for _, key := range held {
+		active = append(active, m.incidents[key].alerts...)
+	}
+	return active
+}
+
+// Events returns the incidents opened and resolved by the latest check.
+// Resolved events carry the incident's last alert at SeverityInfo.
+func (m *Manager) Events() []types.AlertEvent {
+	return append([]types.AlertEvent(nil), m.events...)
+}
+
+// ActiveAlerts returns the alerts of every open incident, including those
+// waiting out the resolve delay.
+func (m *Manager) ActiveAlerts() []types.Alert {
+	keys := make([]string, 0, len(m.incidents))
+	for key := range m.incidents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var active []types.Alert
+	for _, key := range keys {
+		active = append(active, m.incidents[key].alerts...)
+	}
+	return active
+}
+
+// checkNetwork flags RX+TX throughput that has stayed above the threshold
+// for the whole sustain period; it turns critical above twice the threshold.
+func (m *Manager) checkNetwork(status *types.Status, cfg *config.ServiceConfig) (types.Alert, bool) {
+	if status.Network == nil || cfg.NetworkThreshold <= 0 {
+		return types.Alert{}, false
+	}
+
+	now := m.now()
+	rate := (status.Network.RxRate + status.Network.TxRate) / 1024 / 1024
+	if rate <= cfg.NetworkThreshold {
+		m.networkHighSince = time.Time{}
+		return types.Alert{}, false
+	}
+	if m.networkHighSince.IsZero() {
+		m.networkHighSince = now
+	}
+	if now.Sub(m.networkHighSince) < cfg.NetworkSustain {
+		return types.Alert{}, false
+	}
+
+	severity := types.SeverityWarning
+	if rate > cfg.NetworkThreshold*2 {
+		severity = types.SeverityCritical
+	}
+	return types.Alert{
+		Type:     types.AlertTypeNetwork,
+		Severity: severity,
+		Message: fmt.Sprintf("High network throughput: %.2f MB/s for %s (threshold: %.2f MB/s)",
+			rate, now.Sub(m.networkHighSince).Round(time.Second), cfg.NetworkThreshold),
+		Value:     rate,
+		Threshold: cfg.NetworkThreshold,
+		Timestamp: now,
+	}, true
+}
+
+// checkPriority warns while the process runs at a different nice value than
+// when monitoring started, e.g. after being reniced by an operator or tool.
+func (m *Manager) checkPriority(status *types.Status) (types.Alert, bool) {
+	if status.Scheduling == nil {
+		return types.Alert{}, false
+	}
+	nice := status.Scheduling.Nice
+	if m.initialNice == nil {
+		m.initialNice = &nice
+		return types.Alert{}, false
+	}
+	if nice == *m.initialNice {
+		return types.Alert{}, false
+	}
+
+	return types.Alert{
+		Type:      types.AlertTypePriority,
+		Severity:  types.SeverityWarning,
+		Message:   fmt.Sprintf("Process priority changed: nice %d (was %d)", nice, *m.initialNice),
+		Value:     float64(nice),
+		Threshold: float64(*m.initialNice),
+		Timestamp: m.now(),
+	}, true
+}
+
+// checkRestarts counts restarts, seen as a new process start time or as RSS
+// collapsing to a fraction of the previous sample (an in-place re-exec), and
+// flags a crash loop once there are more than RestartLimit within
+// RestartWindow.
+func (m *Manager) checkRestarts(status *types.Status, cfg *config.ServiceConfig) (types.Alert, bool) {
+	now := m.now()
+	restarted := !m.lastStart.IsZero() && !status.StartedAt.IsZero() && !status.StartedAt.Equal(m.lastStart)
+	if m.lastRSS > 0 && status.Memory.RSS > 0 && float64(status.Memory.RSS) < float64(m.lastRSS)*rssCollapseRatio {
+		restarted = true
+	}
+	if restarted {
+		m.restarts = append(m.restarts, now)
 	}
+	if !status.StartedAt.IsZero() {
+		m.lastStart = status.StartedAt
+	}
+	m.lastRSS = status.Memory.RSS
+
+	cutoff := 0
+	for cutoff < len(m.restarts) && now.Sub(m.restarts[cutoff]) > cfg.RestartWindow {
+		cutoff++
+	}
+	m.restarts = m.restarts[cutoff:]
+
+	if cfg.RestartLimit <= 0 || len(m.restarts) <= cfg.RestartLimit {
+		return types.Alert{}, false
+	}
+
+	severity := types.SeverityCritical
+	if len(m.restarts) > cfg.RestartLimit*2 {
+		severity = types.SeverityEmergency
+	}
+	return types.Alert{
+		Type:      types.AlertTypeCrashLoop,
+		Severity:  severity,
+		Message:   fmt.Sprintf("Crash loop: process restarted %d times in the last %s", len(m.restarts), cfg.RestartWindow),
+		Value:     float64(len(m.restarts)),
+		Threshold: float64(cfg.RestartLimit),
+		Timestamp: now,
+	}, true
+}
+
+// checkGCRate flags a GC collection rate above cfg.GCRateThreshold, measured
+// from the running total of collections across the GC rate window. Rising
+// collection counts show allocation pressure before pause times grow.
+func (m *Manager) checkGCRate(status *types.Status, cfg *config.ServiceConfig) (types.Alert, bool) {
+	if cfg.GCRateThreshold <= 0 {
+		return types.Alert{}, false
+	}
+	window := cfg.GCRateWindow
+	if window <= 0 {
+		window = config.DefaultGCRateWindow
+	}
+
+	now := m.now()
+	total := float64(status.GC.CollectionsTotal)
 
-	return alerts
-}
\ No newline at end of file
+	// A total that went down was counted afresh, by a new process or
+	// collector, so earlier totals no longer compare
+	if n := len(m.gcTotals); n > 0 && total < m.gcTotals[n-1].value {
+		m.gcTotals = nil
+	}
+	m.gcTotals = append(m.gcTotals, sample{value: total, at: now})
+
+	// Keep one sample at or before the window start as the baseline
+	for len(m.gcTotals) > 1 && now.Sub(m.gcTotals[1].at) >= window {
+		m.gcTotals = m.gcTotals[1:]
+	}
+
+	oldest := m.gcTotals[0]
+	elapsed := now.Sub(oldest.at)
+	if elapsed < window {
+		return types.Alert{}, false
+	}
+	rate := (total - oldest.value) / elapsed.Seconds()
+
+	band, breached := evaluateBands(rate, []config.SeverityBand{
+		{Above: cfg.GCRateThreshold, Severity: types.SeverityWarning},
+		{Above: cfg.GCRateThreshold * 2, Severity: types.SeverityCritical},
+	})
+	if !breached {
+		return types.Alert{}, false
+	}
+
+	return types.Alert{
+		Type:      types.AlertTypeGCThrashing,
+		Severity:  band.Severity,
+		Message:   fmt.Sprintf("GC thrashing: %.1f collections/s over the last %s (threshold: %.1f/s)", rate, elapsed.Round(time.Second), band.Above),
+		Value:     rate,
+		Threshold: band.Above,
+		Timestamp: now,
+	}, true
+}
+
+// checkStuck flags a service whose throughput counter has not increased for
+// the whole stuck window while no resource threshold is breached.
+func (m *Manager) checkStuck(status *types.Status, cfg *config.ServiceConfig, nominal bool) (types.Alert, bool) {
+	if cfg.StuckMetric == "" {
+		return types.Alert{}, false
+	}
+	value, ok := status.Custom[cfg.StuckMetric]
+	if !ok {
+		return types.Alert{}, false
+	}
+
+	now := m.now()
+	m.throughput = append(m.throughput, sample{value: value, at: now})
+
+	// Keep one sample at or before the window start as the baseline
+	for len(m.throughput) > 1 && now.Sub(m.throughput[1].at) >= cfg.StuckWindow {
+		m.throughput = m.throughput[1:]
+	}
+
+	oldest := m.throughput[0]
+	if !nominal || now.Sub(oldest.at) < cfg.StuckWindow || value > oldest.value {
+		return types.Alert{}, false
+	}
+
+	return types.Alert{
+		Type:      types.AlertTypeStuck,
+		Severity:  types.SeverityWarning,
+		Message:   fmt.Sprintf("Service appears stuck: %s has not increased for %s while CPU and event loop are nominal", cfg.StuckMetric, now.Sub(oldest.at).Round(time.Second)),
+		Value:     value - oldest.value,
+		Threshold: 0,
+		Timestamp: now,
+	}, true
+}
+
+// checkAnomaly raises an anomaly alert when value is more than k standard
+// deviations above the mean of the trailing samples of rule r. A history
+// without any variation gives no scale to measure against and never alerts.
+func (m *Manager) checkAnomaly(r rule, status *types.Status, value float64, trailing []float64, k float64) (types.Alert, bool) {
+	mean, stddev := meanStddev(trailing)
+	if stddev == 0 {
+		return types.Alert{}, false
+	}
+
+	threshold := mean + k*stddev
+	if value <= threshold {
+		return types.Alert{}, false
+	}
+
+	z := (value - mean) / stddev
+	return types.Alert{
+		Type:      types.AlertTypeAnomaly,
+		Severity:  types.SeverityWarning,
+		Message:   fmt.Sprintf("%s [anomaly: z-score %.1f against trailing mean %.2f, stddev %.2f]", r.message(status, value, threshold), z, mean, stddev),
+		Value:     value,
+		Threshold: threshold,
+		Timestamp: m.now(),
+	}, true
+}
+
+// checkBlocked flags an event loop that has not run the lag measurement on
+// blockedTimeouts polls in a row.
+func (m *Manager) checkBlocked(status *types.Status) (types.Alert, bool) {
+	if status.EventLoop.Timeouts < blockedTimeouts {
+		return types.Alert{}, false
+	}
+	return types.Alert{
+		Type:      types.AlertTypeEventLoopBlocked,
+		Severity:  types.SeverityCritical,
+		Message:   fmt.Sprintf("Event loop blocked for %s: %d consecutive lag measurements timed out, likely synchronous work on the main thread", (time.Duration(status.EventLoop.BlockedMs) * time.Millisecond).Round(100*time.Millisecond), status.EventLoop.Timeouts),
+		Value:     float64(status.EventLoop.Timeouts),
+		Threshold: blockedTimeouts,
+		Timestamp: m.now(),
+	}, true
+}
+
+// evaluateBands returns the most severe band whose lower bound value exceeds.
+func evaluateBands(value float64, bands []config.SeverityBand) (config.SeverityBand, bool) {
+	var matched config.SeverityBand
+	breached := false
+	for _, band := range bands {
+		if value <= band.Above {
+			continue
+		}
+		if !breached || band.Severity.Rank() > matched.Severity.Rank() {
+			matched = band
+			breached = true
+		}
+	}
+	return matched, breached
+}
@@ -0,0 +1,140 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"stackpulse/internal/config"
+	"stackpulse/internal/types"
+)
+
+// TestTrackFlapping feeds CPU usage polled once a second around a threshold
+// of 70 and checks the incident after every poll: F fired, A active without
+// a transition, R resolved, - no incident.
+func TestTrackFlapping(t *testing.T) {
+	tests := []struct {
+		name         string
+		resolveAfter time.Duration
+		consecutive  int
+		usage        []float64
+		want         string
+	}{
+		{
+			name:         "dip shorter than resolve-after holds",
+			resolveAfter: 3 * time.Second,
+			consecutive:  1,
+			usage:        []float64{80, 60, 60, 80, 80},
+			want:         "FAAAA",
+		},
+		{
+			name:         "resolves once clear for resolve-after",
+			resolveAfter: 3 * time.Second,
+			consecutive:  1,
+			usage:        []float64{80, 60, 60, 60, 60, 60},
+			want:         "FAAAR-",
+		},
+		{
+			name:         "oscillating around the threshold fires once",
+			resolveAfter: 3 * time.Second,
+			consecutive:  1,
+			usage:        []float64{71, 69, 71, 69, 71, 69, 71, 69},
+			want:         "FAAAAAAA",
+		},
+		{
+			name:         "oscillation never reaches the consecutive count",
+			resolveAfter: 0,
+			consecutive:  3,
+			usage:        []float64{71, 69, 71, 71, 69, 71, 69, 71, 71},
+			want:         "---------",
+		},
+		{
+			name:         "consecutive breaches fire and clears resolve",
+			resolveAfter: 0,
+			consecutive:  2,
+			usage:        []float64{71, 71, 69, 71, 69, 69, 69},
+			want:         "-FAAAR-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ServiceConfig{
+				CPUThreshold:     70,
+				ResolveAfter:     tt.resolveAfter,
+				AlertConsecutive: tt.consecutive,
+			}
+
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			now := start
+			m := NewManager()
+			m.SetClock(func() time.Time { return now })
+
+			var got []byte
+			for i, usage := range tt.usage {
+				now = start.Add(time.Duration(i) * time.Second)
+				status := &types.Status{PID: 42, Timestamp: now, CPU: types.CPUMetrics{Usage: usage}}
+				active := m.CheckThresholds(status, cfg)
+
+				state := byte('-')
+				for _, alert := range active {
+					if alert.Type == types.AlertTypeCPU {
+						state = 'A'
+					}
+				}
+				for _, event := range m.Events() {
+					if event.Alert.Type != types.AlertTypeCPU {
+						continue
+					}
+					if event.Resolved {
+						state = 'R'
+					} else {
+						state = 'F'
+					}
+				}
+				got = append(got, state)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("usage %v: got %s, want %s", tt.usage, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRuleMetrics keeps config.RuleMetrics, which validates the metrics
+// given for relative thresholds and anomaly detection, in step with the
+// rule table.
+func TestRuleMetrics(t *testing.T) {
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		names[r.name] = true
+		if !config.RuleMetrics[r.name] {
+			t.Errorf("rule %q is missing from config.RuleMetrics", r.name)
+		}
+	}
+	for metric := range config.RuleMetrics {
+		if !names[metric] {
+			t.Errorf("config.RuleMetrics lists %q, which has no rule", metric)
+		}
+	}
+}
+
+// TestHandlesNeedInspector checks that the handles rule is skipped while
+// the handle counts were not read from the inspector.
+func TestHandlesNeedInspector(t *testing.T) {
+	cfg := &config.ServiceConfig{CPUThreshold: 70}
+	status := &types.Status{PID: 42, Timestamp: time.Now(), Handles: types.HandleMetrics{Active: 500}}
+
+	for _, inspected := range []bool{false, true} {
+		status.Sources.Handles = inspected
+		raised := false
+		for _, alert := range NewManager().CheckThresholds(status, cfg) {
+			if alert.Type == types.AlertTypeHandles {
+				raised = true
+			}
+		}
+		if raised != inspected {
+			t.Errorf("with Sources.Handles %v: handles alert raised = %v, want %v", inspected, raised, inspected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package alerts
+
+import (
+	"time"
+
+	"stackpulse/internal/config"
+)
+
+// warmupScale returns the factor thresholds are multiplied by at now. It
+// starts at cfg.WarmupFactor when monitoring begins and falls linearly to 1
+// over cfg.Warmup, so a process that is broken from the start still alerts
+// while normal startup spikes do not.
+func (m *Manager) warmupScale(cfg *config.ServiceConfig, now time.Time) float64 {
+	if m.started.IsZero() {
+		m.started = now
+	}
+	if cfg.Warmup <= 0 || cfg.WarmupFactor <= 1 {
+		return 1
+	}
+
+	elapsed := now.Sub(m.started)
+	if elapsed >= cfg.Warmup {
+		return 1
+	}
+	remaining := 1 - float64(elapsed)/float64(cfg.Warmup)
+	return 1 + (cfg.WarmupFactor-1)*remaining
+}
+
+// scaleBands returns a copy of bands with every threshold multiplied by scale.
+func scaleBands(bands []config.SeverityBand, scale float64) []config.SeverityBand {
+	scaled := make([]config.SeverityBand, len(bands))
+	for i, band := range bands {
+		scaled[i] = band
+		scaled[i].Above = band.Above * scale
+	}
+	return scaled
+}
@@ -0,0 +1,140 @@
+// Package api serves monitor snapshots over HTTP, as JSON and in the
+// Prometheus text format.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"stackpulse/internal/export"
+	"stackpulse/internal/store"
+	"stackpulse/internal/types"
+)
+
+// AlertSource reports the alerts currently firing, e.g. a monitor.Monitor.
+type AlertSource interface {
+	ActiveAlerts() []types.Alert
+}
+
+// Server serves the latest status at GET /status, recent history at
+// GET /metrics/history, the active alerts at GET /alerts, and the latest
+// status for Prometheus scrapes at GET /metrics.
+type Server struct {
+	samples *store.Store
+	alerts  AlertSource
+}
+
+// NewServer serves the statuses in samples, which the monitor keeps
+// pushing to, and the active alerts of alerts; nil alerts serves none.
+func NewServer(samples *store.Store, alerts AlertSource) *Server {
+	return &Server{samples: samples, alerts: alerts}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/metrics/history", s.handleHistory)
+	mux.HandleFunc("/alerts", s.handleAlerts)
+	return mux
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	status := s.samples.Latest()
+	if status == nil {
+		writeError(w, http.StatusServiceUnavailable, "no status collected yet")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := export.WritePrometheus(w, status); err != nil {
+		log.Printf("Warning: Failed to write Prometheus metrics: %v", err)
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	status := s.samples.Latest()
+	if status == nil {
+		writeError(w, http.StatusServiceUnavailable, "no status collected yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, export.NewStatusEnvelope(status))
+}
+
+// historyPoint is one sample of a metric in a GET /metrics/history response.
+type historyPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// handleHistory serves the samples of ?metric= taken within ?since= (a
+// duration, default everything retained), oldest first.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	name := r.URL.Query().Get("metric")
+	if !store.IsMetric(name) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown metric %q (expected %s)", name, strings.Join(store.Metrics(), ", ")))
+		return
+	}
+
+	var cutoff time.Time
+	if since := r.URL.Query().Get("since"); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid since %q (expected a duration such as 5m)", since))
+			return
+		}
+		cutoff = time.Now().Add(-d)
+	}
+
+	points := []historyPoint{}
+	for _, status := range s.samples.Since(cutoff) {
+		value, _ := store.Value(name, &status)
+		points = append(points, historyPoint{Timestamp: status.Timestamp, Value: value})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"metric": name,
+		"points": points,
+	})
+}
+
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	alerts := []types.Alert{}
+	if s.alerts != nil {
+		alerts = append(alerts, s.alerts.ActiveAlerts()...)
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandFunc handles one socket command. arg is the rest of the request
+// line after the command name.
+type CommandFunc func(arg string) (interface{}, error)
+
+// SocketServer accepts line-oriented commands on a Unix domain socket. Each
+// request is a single line ("annotate deploy v1.2.3"), answered with a
+// single JSON line.
+type SocketServer struct {
+	path     string
+	listener net.Listener
+
+	mu       sync.RWMutex
+	commands map[string]CommandFunc
+}
+
+type socketResponse struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// ListenSocket opens the socket at path, replacing a stale socket file left
+// behind by a previous watcher.
+func ListenSocket(path string) (*SocketServer, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another watcher is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return &SocketServer{
+		path:     path,
+		listener: listener,
+		commands: make(map[string]CommandFunc),
+	}, nil
+}
+
+// Handle registers fn for command.
+func (s *SocketServer) Handle(command string, fn CommandFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands[command] = fn
+}
+
+// Serve accepts connections until the server is closed.
+func (s *SocketServer) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *SocketServer) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *SocketServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+	command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+	s.mu.RLock()
+	fn, ok := s.commands[command]
+	s.mu.RUnlock()
+
+	var resp socketResponse
+	if !ok {
+		resp.Error = fmt.Sprintf("unknown command %q", command)
+	} else if result, err := fn(strings.TrimSpace(arg)); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Result = result
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		log.Printf("Warning: Failed to answer socket command %q: %v", command, err)
+	}
+}
+
+// SendCommand sends one command to the watcher listening at path and decodes
+// its result into result (which may be nil).
+func SendCommand(path, command, arg string, result interface{}) error {
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("no active watcher found at %s: %w", path, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := fmt.Fprintf(conn, "%s %s\n", command, arg); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read watcher response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode watcher response: %w", err)
+		}
+	}
+	return nil
+}
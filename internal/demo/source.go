@@ -0,0 +1,168 @@
+// Package demo generates synthetic metrics for trying out StackPulse without
+// a misbehaving Node.js service.
+package demo
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// PID reported for the synthetic target
+const PID = 4242
+
+// Memory the synthetic target starts at, and the point at which it
+// "restarts" so a long demo keeps cycling through its phases
+const (
+	baseRSS    = 80 * 1024 * 1024
+	restartRSS = 320 * 1024 * 1024
+)
+
+// Options controls the problems the synthetic target exhibits. A zero
+// interval or rate disables that behaviour.
+type Options struct {
+	SpikeEvery  time.Duration // CPU spike period
+	SpikeLength time.Duration
+	LeakRate    float64       // RSS growth in MB per second
+	StallEvery  time.Duration // event loop stall period
+	StallLength time.Duration
+}
+
+// DefaultOptions shows every alert type within the first minute.
+func DefaultOptions() Options {
+	return Options{
+		SpikeEvery:  20 * time.Second,
+		SpikeLength: 5 * time.Second,
+		LeakRate:    2,
+		StallEvery:  15 * time.Second,
+		StallLength: 3 * time.Second,
+	}
+}
+
+// Source produces synthetic statuses: a steady baseline with periodic CPU
+// spikes, event loop stalls, and memory that grows until it restarts.
+type Source struct {
+	opts    Options
+	start   time.Time
+	restart time.Time
+	rng     *rand.Rand
+	gcTotal int
+}
+
+func NewSource(opts Options) *Source {
+	now := time.Now()
+	return &Source{
+		opts:    opts,
+		start:   now,
+		restart: now,
+		rng:     rand.New(rand.NewSource(now.UnixNano())),
+	}
+}
+
+func (s *Source) Sample(ctx context.Context) (*types.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(s.start)
+
+	cpu := 15 + s.rng.Float64()*10
+	if inPhase(elapsed, s.opts.SpikeEvery, s.opts.SpikeLength) {
+		cpu = 88 + s.rng.Float64()*10
+	}
+
+	lag := 0.5 + s.rng.Float64()*1.5
+	utilization := 20 + s.rng.Float64()*15
+	if inPhase(elapsed, s.opts.StallEvery, s.opts.StallLength) {
+		lag = 25 + s.rng.Float64()*30
+		utilization = 92 + s.rng.Float64()*8
+	}
+
+	rss := uint64(baseRSS + s.opts.LeakRate*1024*1024*now.Sub(s.restart).Seconds())
+	if rss > restartRSS {
+		s.restart = now
+		rss = baseRSS
+	}
+	heapTotal := rss * 3 / 4
+	heapUsed := heapTotal*55/100 + uint64(s.rng.Int63n(int64(heapTotal/5)))
+
+	gcDuration := 1 + s.rng.Float64()*4
+	s.gcTotal++
+
+	return &types.Status{
+		PID: PID,
+		CPU: types.CPUMetrics{
+			Usage:     cpu,
+			Timestamp: now,
+		},
+		Memory: types.MemoryMetrics{
+			RSS:       rss,
+			VMS:       rss * 4,
+			HeapTotal: heapTotal,
+			HeapUsed:  heapUsed,
+			External:  2 * 1024 * 1024,
+			Timestamp: now,
+		},
+		EventLoop: types.EventLoopMetrics{
+			Lag:         lag,
+			Mean:        lag,
+			Max:         lag * 1.5,
+			Min:         lag / 2,
+			P95:         lag * 1.3,
+			Utilization: utilization,
+			Timestamp:   now,
+		},
+		ThreadPool: types.ThreadPoolMetrics{
+			PoolSize:  4,
+			Timestamp: now,
+		},
+		GC: types.GCMetrics{
+			Collections:       1,
+			Duration:          gcDuration,
+			HeapSizeBefore:    heapUsed,
+			HeapSizeAfter:     heapUsed * 3 / 4,
+			Type:              "scavenge",
+			Reason:            "allocation failure",
+			CollectionsTotal:  s.gcTotal,
+			DurationTotal:     float64(s.gcTotal) * 2.5,
+			ReclaimEfficiency: 0.25,
+			Timestamp:         now,
+		},
+		Handles: types.HandleMetrics{
+			Active:     20 + s.rng.Intn(10),
+			Timers:     5,
+			TCPSockets: 12,
+			Timestamp:  now,
+		},
+		V8: types.V8Metrics{
+			HeapSpaceUsed:      map[string]uint64{"new_space": heapUsed / 8, "old_space": heapUsed * 7 / 8},
+			HeapSpaceSize:      map[string]uint64{"new_space": heapTotal / 8, "old_space": heapTotal * 7 / 8},
+			HeapSpaceAvailable: map[string]uint64{},
+			Timestamp:          now,
+		},
+		Timestamp: now,
+
+		// The synthetic target stands in for a fully inspected process
+		InspectorAvailable: true,
+		Sources: types.SourceAvailability{
+			HeapUsage:  true,
+			EventLoop:  true,
+			ThreadPool: true,
+			GC:         true,
+			Handles:    true,
+			V8:         true,
+		},
+	}, nil
+}
+
+// inPhase reports whether elapsed falls in the last length of a period, so
+// the demo starts healthy and the first problem shows up after one period.
+func inPhase(elapsed, every, length time.Duration) bool {
+	if every <= 0 {
+		return false
+	}
+	return elapsed%every >= every-length
+}
@@ -0,0 +1,106 @@
+// Package diagnostics assembles triage reports for critical incidents.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"stackpulse/internal/metrics"
+	"stackpulse/internal/types"
+)
+
+// Report bundles what a responder needs to start triage of one incident.
+type Report struct {
+	IncidentID  string         `json:"incidentId"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Process     ProcessInfo    `json:"process"`
+	NodeVersion string         `json:"nodeVersion,omitempty"`
+	Alerts      []types.Alert  `json:"alerts"`
+	History     []types.Status `json:"history"`
+}
+
+// ProcessInfo describes the monitored process at report time.
+type ProcessInfo struct {
+	PID        int       `json:"pid"`
+	Cmdline    string    `json:"cmdline"`
+	StartTime  time.Time `json:"startTime"`
+	Uptime     float64   `json:"uptimeSeconds"`
+	FDCount    int32     `json:"fdCount"`
+	NumThreads int32     `json:"numThreads"`
+}
+
+// Diagnostics gathers a report from the monitor's existing sources: the
+// alerts and recent statuses it already holds, the process table, and the
+// inspector.
+type Diagnostics struct {
+	IncidentID  string
+	PID         int
+	InspectPort int
+	Collector   *metrics.Collector
+	Alerts      []types.Alert
+	History     []types.Status
+}
+
+// Collect assembles the report. Process details and the Node version are
+// best effort: a failure is logged and the field left empty so the report
+// is still produced.
+func (d *Diagnostics) Collect(ctx context.Context) (*Report, error) {
+	report := &Report{
+		IncidentID:  d.IncidentID,
+		GeneratedAt: time.Now(),
+		Process:     ProcessInfo{PID: d.PID},
+		Alerts:      d.Alerts,
+		History:     d.History,
+	}
+
+	if err := d.collectProcess(&report.Process); err != nil {
+		log.Printf("Warning: Failed to collect process info for report: %v", err)
+	}
+
+	version, err := d.Collector.NodeVersion(ctx, d.InspectPort)
+	if err != nil {
+		log.Printf("Warning: Failed to read Node version for report: %v", err)
+	}
+	report.NodeVersion = version
+
+	return report, nil
+}
+
+func (d *Diagnostics) collectProcess(info *ProcessInfo) error {
+	proc, err := process.NewProcess(int32(d.PID))
+	if err != nil {
+		return fmt.Errorf("failed to get process %d: %w", d.PID, err)
+	}
+
+	if info.Cmdline, err = proc.Cmdline(); err != nil {
+		return fmt.Errorf("failed to get command line: %w", err)
+	}
+	createTime, err := proc.CreateTime()
+	if err != nil {
+		return fmt.Errorf("failed to get start time: %w", err)
+	}
+	info.StartTime = time.UnixMilli(createTime)
+	info.Uptime = time.Since(info.StartTime).Seconds()
+
+	// Not available on every platform
+	info.FDCount, _ = proc.NumFDs()
+	info.NumThreads, _ = proc.NumThreads()
+	return nil
+}
+
+// WriteReport writes report to path as indented JSON.
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diagnostic report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write diagnostic report: %w", err)
+	}
+	return nil
+}
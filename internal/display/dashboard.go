@@ -2,33 +2,268 @@ package display
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"strings"
-	"os"
 	"os/exec"
 	"runtime"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"stackpulse/internal/config"
 	"stackpulse/internal/types"
 )
 
 type Dashboard struct {
+	// Guards rendering, which key presses trigger from their own goroutine
+	mu sync.Mutex
+
+	// Where frames are drawn: the terminal, or the TUI's buffer
+	out io.Writer
+
 	lastUpdate time.Time
+
+	// Redraw throttling: skip renders while every metric stays within
+	// epsilon (relative) of the last render, but redraw at least every
+	// maxInterval. Disabled when epsilon is 0.
+	epsilon      float64
+	maxInterval  time.Duration
+	lastRendered *types.Status
+
+	// Whether Update only keeps statuses for the next Refresh, and the
+	// latest one kept since the previous
+	deferred bool
+	pending  *types.Status
+
+	glyphs Glyphs
+	theme  Theme
+
+	// Warning and critical levels of the CPU row, in the scale of the CPU
+	// samples
+	cpuWarning  float64
+	cpuCritical float64
+
+	// RSS limit in bytes; the memory row is critical at 4/3 of it
+	memoryLimit float64
+
+	// Unit of the byte sizes shown
+	units UnitMode
+
+	// Warning and critical levels of the heap, event loop, GC and handle rows
+	thresholds config.Thresholds
+
+	// Index into focusGroups of the expanded group, or -1 for the overview;
+	// keys is set once key presses are read
+	focus   int
+	keys    bool
+	history func() []types.Status
+
+	// Whether the overview table has a trend column drawn from history
+	sparklines bool
+
+	// Reports whether a metric group is collected; rows of the others are
+	// hidden. Nil shows every group.
+	collects func(group string) bool
 }
 
 func NewDashboard() *Dashboard {
-	return &Dashboard{}
+	glyphs, _ := LookupGlyphs("")
+	theme, _ := LookupTheme("")
+	return &Dashboard{out: color.Output, glyphs: glyphs, theme: theme, cpuWarning: 70, cpuCritical: 90, memoryLimit: 150 << 20, thresholds: config.DefaultThresholds(), focus: -1, sparklines: true}
+}
+
+// SetThresholds sets the levels at which the heap, event loop, GC and handle
+// rows show high and critical.
+func (d *Dashboard) SetThresholds(thresholds config.Thresholds) {
+	d.thresholds = thresholds
+}
+
+// SetCPUThresholds sets the CPU usage above which the CPU row shows high
+// and critical.
+func (d *Dashboard) SetCPUThresholds(warning, critical float64) {
+	d.cpuWarning = warning
+	d.cpuCritical = critical
+}
+
+// SetHistory sets where the focus view and the trend column read recent
+// samples from.
+func (d *Dashboard) SetHistory(history func() []types.Status) {
+	d.history = history
+}
+
+// SetSparklines shows or hides the trend column of the overview table.
+func (d *Dashboard) SetSparklines(enabled bool) {
+	d.sparklines = enabled
+}
+
+// SetCollects sets which metric groups are collected (see
+// config.ServiceConfig.Collects), hiding the rows of the others.
+func (d *Dashboard) SetCollects(collects func(group string) bool) {
+	d.collects = collects
+}
+
+// shows reports whether the rows of a metric group are drawn.
+func (d *Dashboard) shows(group string) bool {
+	return d.collects == nil || d.collects(group)
+}
+
+// SetMemoryLimit sets the RSS, in bytes, above which the memory row shows
+// high.
+func (d *Dashboard) SetMemoryLimit(limit uint64) {
+	d.memoryLimit = float64(limit)
+}
+
+// SetUnits sets the unit byte sizes are shown in.
+func (d *Dashboard) SetUnits(mode UnitMode) {
+	d.units = mode
+}
+
+// SetGlyphs changes the status and section indicators.
+func (d *Dashboard) SetGlyphs(glyphs Glyphs) {
+	d.glyphs = glyphs
+}
+
+// SetTheme changes the palette.
+func (d *Dashboard) SetTheme(theme Theme) {
+	d.theme = theme
+}
+
+// SetThrottle enables change-based redraw throttling.
+func (d *Dashboard) SetThrottle(epsilon float64, maxInterval time.Duration) {
+	d.epsilon = epsilon
+	d.maxInterval = maxInterval
+}
+
+// SetDeferred makes Update keep each status for the next Refresh instead
+// of drawing it, so the screen is repainted on its own cadence however fast
+// samples arrive. The first status is still drawn at once.
+func (d *Dashboard) SetDeferred(deferred bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deferred = deferred
 }
 
 func (d *Dashboard) Update(status *types.Status) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.deferred && d.lastRendered != nil {
+		d.pending = status
+		return
+	}
+	d.draw(status)
+}
+
+// Refresh draws the latest status Update kept since the previous refresh,
+// if any arrived.
+func (d *Dashboard) Refresh() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pending == nil {
+		return
+	}
+	status := d.pending
+	d.pending = nil
+	d.draw(status)
+}
+
+func (d *Dashboard) draw(status *types.Status) {
+	if !d.shouldRender(status) {
+		return
+	}
+	d.lastRendered = status
+	d.render(status)
+}
+
+func (d *Dashboard) render(status *types.Status) {
 	d.clearScreen()
 	d.displayHeader()
-	d.displayMetrics(status)
-	d.displayAlerts(status.Alerts)
+	d.displayDegraded(status)
+	if d.focus >= 0 {
+		d.displayFocus(status)
+		d.displayAlerts(status.Alerts)
+	} else {
+		d.displayMetrics(status)
+		d.displayAnnotations(status)
+		d.displayAlerts(status.Alerts)
+		d.displayAlertHistory(status.AlertEvents)
+	}
 	d.lastUpdate = time.Now()
 }
 
+// displayDegraded warns when the Node-specific metrics of status are
+// estimates because the inspector could not be read.
+func (d *Dashboard) displayDegraded(status *types.Status) {
+	if status.InspectorAvailable {
+		return
+	}
+	degradedColor := text(d.theme.Warning).Add(color.Bold)
+	degradedColor.Fprintf(d.out, "%s\n\n", title(d.glyphs.Warning, "Inspector unavailable: Node-specific metrics are estimates"))
+}
+
+func (d *Dashboard) shouldRender(status *types.Status) bool {
+	if d.epsilon <= 0 || d.lastRendered == nil {
+		return true
+	}
+	if d.maxInterval > 0 && time.Since(d.lastUpdate) >= d.maxInterval {
+		return true
+	}
+
+	prev := d.lastRendered
+	if alertState(prev.Alerts) != alertState(status.Alerts) {
+		return true
+	}
+	if latestMarker(prev) != latestMarker(status) {
+		return true
+	}
+
+	pairs := [][2]float64{
+		{prev.CPU.Percent(), status.CPU.Percent()},
+		{float64(prev.Memory.RSS), float64(status.Memory.RSS)},
+		{float64(prev.Memory.HeapUsed), float64(status.Memory.HeapUsed)},
+		{float64(prev.Memory.HeapTotal), float64(status.Memory.HeapTotal)},
+		{prev.EventLoop.Lag, status.EventLoop.Lag},
+		{prev.EventLoop.Utilization, status.EventLoop.Utilization},
+		{prev.GC.Duration, status.GC.Duration},
+		{float64(prev.Handles.Active), float64(status.Handles.Active)},
+	}
+	for _, p := range pairs {
+		if relativeChange(p[0], p[1]) > d.epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// latestMarker returns the timestamp of the newest annotation on status.
+func latestMarker(status *types.Status) time.Time {
+	if len(status.Annotations) == 0 {
+		return time.Time{}
+	}
+	return status.Annotations[len(status.Annotations)-1].Timestamp
+}
+
+func relativeChange(old, new float64) float64 {
+	base := math.Max(math.Abs(old), math.Abs(new))
+	if base == 0 {
+		return 0
+	}
+	return math.Abs(new-old) / base
+}
+
+// alertState summarizes which alerts are active at which severity.
+func alertState(alerts []types.Alert) string {
+	parts := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		parts = append(parts, string(alert.Type)+":"+string(alert.Severity))
+	}
+	return strings.Join(parts, ",")
+}
+
 func (d *Dashboard) clearScreen() {
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
@@ -36,225 +271,269 @@ func (d *Dashboard) clearScreen() {
 	} else {
 		cmd = exec.Command("clear")
 	}
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = d.out
 	cmd.Run()
 }
 
 func (d *Dashboard) displayHeader() {
-	headerColor := color.New(color.FgCyan, color.Bold)
-	headerColor.Println("╔══════════════════════════════════════════════════════════════════════════════╗")
-	headerColor.Println("║                            STACKPULSE DASHBOARD                              ║")
-	headerColor.Println("╚══════════════════════════════════════════════════════════════════════════════╝")
-	fmt.Printf("Last Update: %s\n\n", d.lastUpdate.Format("15:04:05.000"))
+	headerColor := text(d.theme.Header)
+	headerColor.Fprintln(d.out, "╔══════════════════════════════════════════════════════════════════════════════╗")
+	headerColor.Fprintln(d.out, "║                            STACKPULSE DASHBOARD                              ║")
+	headerColor.Fprintln(d.out, "╚══════════════════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintf(d.out, "Last Update: %s\n", d.lastUpdate.Format("15:04:05.000"))
+	if d.keys {
+		fmt.Fprint(d.out, "Keys: 1 CPU  2 Memory  3 Heap  4 Event Loop  5 GC  6 Handles  Tab next  0 overview\n")
+	}
+	fmt.Fprintln(d.out)
 }
 
 func (d *Dashboard) displayMetrics(status *types.Status) {
 	// Service info
-	serviceColor := color.New(color.FgGreen, color.Bold)
-	serviceColor.Printf("🔍 Monitoring PID: %d\n\n", status.PID)
+	uptime := ""
+	if status.Uptime > 0 {
+		uptime = fmt.Sprintf(" (up %s)", time.Duration(status.Uptime*float64(time.Second)).Round(time.Second))
+	}
+	text(d.theme.Title).Fprintf(d.out, "%s: %d%s\n\n", title(d.glyphs.Monitor, "Monitoring PID"), status.PID, uptime)
 
 	// Create table for metrics
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Metric", "Current", "Status", "Threshold"})
+	header := []string{"Metric", "Current", "Status", "Threshold"}
+	if d.sparklines {
+		header = append(header, "Trend")
+	}
+	headerColors := make([]tablewriter.Colors, len(header))
+	for i := range headerColors {
+		headerColors[i] = cell(d.theme.Header)
+	}
+	table := tablewriter.NewWriter(d.out)
+	table.SetHeader(header)
 	table.SetBorder(true)
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-	)
+	table.SetHeaderColor(headerColors...)
 
-	// CPU metrics
-	cpuStatus := "✅ Normal"
-	cpuColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if status.CPU.Usage > 70 {
-		cpuStatus = "⚠️  High"
-		cpuColor = tablewriter.Colors{tablewriter.FgYellowColor}
+	// Each row ends with a sparkline of its metric over the recent history
+	var history []types.Status
+	if d.sparklines {
+		history = d.recentHistory(status, sparklineWidth)
 	}
-	if status.CPU.Usage > 90 {
-		cpuStatus = "🚨 Critical"
-		cpuColor = tablewriter.Colors{tablewriter.FgRedColor}
+	row := func(cells []string, colors []tablewriter.Colors, value func(s *types.Status) float64) {
+		if d.sparklines {
+			values := make([]float64, len(history))
+			for i := range history {
+				values[i] = value(&history[i])
+			}
+			cells = append(cells, Sparkline(values))
+			colors = append(colors, tablewriter.Colors{})
+		}
+		table.Rich(cells, colors)
 	}
 
-	table.Rich([]string{
-		"CPU Usage",
-		fmt.Sprintf("%.2f%%", status.CPU.Usage),
+	// CPU metrics
+	cpuStatus, cpuColor := d.statusCell(status.CPU.Percent(), d.cpuWarning, d.cpuCritical)
+	row([]string{
+		cpuLabel(status.CPU),
+		cpuUsageText(status.CPU),
 		cpuStatus,
-		"< 70%",
-	}, []tablewriter.Colors{{}, cpuColor, cpuColor, {}})
-
-	// Memory metrics
-	memoryMB := float64(status.Memory.RSS) / 1024 / 1024
-	memoryStatus := "✅ Normal"
-	memoryColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if memoryMB > 100 {
-		memoryStatus = "⚠️  High"
-		memoryColor = tablewriter.Colors{tablewriter.FgYellowColor}
-	}
-	if memoryMB > 200 {
-		memoryStatus = "🚨 Critical"
-		memoryColor = tablewriter.Colors{tablewriter.FgRedColor}
+		fmt.Sprintf("< %.0f%%", d.cpuWarning),
+	}, []tablewriter.Colors{{}, d.gradientColor(status.CPU.Percent() / d.cpuWarning), cpuColor, {}},
+		func(s *types.Status) float64 { return s.CPU.Percent() })
+
+	// Memory metrics; the limit is written in the binary units --heap-limit
+	// is parsed in, so 150MB never shows as 157.3 MB
+	rss := float64(status.Memory.RSS)
+	memoryStatus, memoryColor := d.statusCell(rss, d.memoryLimit, d.memoryLimit*4/3)
+	limitUnits := d.units
+	if limitUnits == UnitsSI {
+		limitUnits = UnitsIEC
 	}
-
-	table.Rich([]string{
+	row([]string{
 		"Memory (RSS)",
-		fmt.Sprintf("%.1f MB", memoryMB),
+		memoryText(status.Memory, d.units),
 		memoryStatus,
-		"< 150 MB",
-	}, []tablewriter.Colors{{}, memoryColor, memoryColor, {}})
+		"< " + FormatBytes(uint64(d.memoryLimit), limitUnits),
+	}, []tablewriter.Colors{{}, d.gradientColor(rss / d.memoryLimit), memoryColor, {}},
+		func(s *types.Status) float64 { return float64(s.Memory.RSS) })
 
 	// Heap metrics
-	if status.Memory.HeapTotal > 0 {
-		heapUsedMB := float64(status.Memory.HeapUsed) / 1024 / 1024
-		heapTotalMB := float64(status.Memory.HeapTotal) / 1024 / 1024
-		heapUsage := (float64(status.Memory.HeapUsed) / float64(status.Memory.HeapTotal)) * 100
-
-		heapStatus := "✅ Normal"
-		heapColor := tablewriter.Colors{tablewriter.FgGreenColor}
-		if heapUsage > 80 {
-			heapStatus = "⚠️  High"
-			heapColor = tablewriter.Colors{tablewriter.FgYellowColor}
-		}
-		if heapUsage > 95 {
-			heapStatus = "🚨 Critical"
-			heapColor = tablewriter.Colors{tablewriter.FgRedColor}
-		}
-
-		table.Rich([]string{
+	if heapUsage, ok := types.HeapUsagePercent(status.Memory); ok {
+		heapStatus, heapColor := d.statusCell(heapUsage, d.thresholds.Heap.Warning, d.thresholds.Heap.Critical)
+		row([]string{
 			"Heap Usage",
-			fmt.Sprintf("%.1f/%.1f MB (%.1f%%)", heapUsedMB, heapTotalMB, heapUsage),
+			fmt.Sprintf("%s/%s (%.1f%%)", FormatBytes(status.Memory.HeapUsed, d.units), FormatBytes(status.Memory.HeapTotal, d.units), heapUsage),
 			heapStatus,
-			"< 80%",
-		}, []tablewriter.Colors{{}, heapColor, heapColor, {}})
-	}
-
-	// Event loop lag
-	lagStatus := "✅ Normal"
-	lagColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if status.EventLoop.Lag > 5 {
-		lagStatus = "⚠️  High"
-		lagColor = tablewriter.Colors{tablewriter.FgYellowColor}
+			fmt.Sprintf("< %.0f%%", d.thresholds.Heap.Warning),
+		}, []tablewriter.Colors{{}, d.gradientColor(heapUsage / d.thresholds.Heap.Warning), heapColor, {}},
+			func(s *types.Status) float64 { return float64(s.Memory.HeapUsed) })
 	}
-	if status.EventLoop.Lag > 20 {
-		lagStatus = "🚨 Critical"
-		lagColor = tablewriter.Colors{tablewriter.FgRedColor}
-	}
-
-	table.Rich([]string{
-		"Event Loop Lag",
-		fmt.Sprintf("%.2f ms", status.EventLoop.Lag),
-		lagStatus,
-		"< 5 ms",
-	}, []tablewriter.Colors{{}, lagColor, lagColor, {}})
 
-	// Event loop utilization
-	utilizationStatus := "✅ Normal"
-	utilizationColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if status.EventLoop.Utilization > 70 {
-		utilizationStatus = "⚠️  High"
-		utilizationColor = tablewriter.Colors{tablewriter.FgYellowColor}
-	}
-	if status.EventLoop.Utilization > 90 {
-		utilizationStatus = "🚨 Critical"
-		utilizationColor = tablewriter.Colors{tablewriter.FgRedColor}
+	// Event loop lag and utilization
+	if d.shows("eventloop") {
+		lagStatus, lagColor := d.statusCell(status.EventLoop.Lag, d.thresholds.Lag.Warning, d.thresholds.Lag.Critical)
+		row([]string{
+			"Event Loop Lag",
+			fmt.Sprintf("%.2f ms", status.EventLoop.Lag),
+			lagStatus,
+			fmt.Sprintf("< %g ms", d.thresholds.Lag.Warning),
+		}, []tablewriter.Colors{{}, d.gradientColor(status.EventLoop.Lag / d.thresholds.Lag.Warning), lagColor, {}},
+			func(s *types.Status) float64 { return s.EventLoop.Lag })
+
+		utilizationStatus, utilizationColor := d.statusCell(status.EventLoop.Utilization, d.thresholds.Utilization.Warning, d.thresholds.Utilization.Critical)
+		row([]string{
+			"Event Loop Util",
+			fmt.Sprintf("%.1f%%", status.EventLoop.Utilization),
+			utilizationStatus,
+			fmt.Sprintf("< %.0f%%", d.thresholds.Utilization.Warning),
+		}, []tablewriter.Colors{{}, d.gradientColor(status.EventLoop.Utilization / d.thresholds.Utilization.Warning), utilizationColor, {}},
+			func(s *types.Status) float64 { return s.EventLoop.Utilization })
 	}
 
-	table.Rich([]string{
-		"Event Loop Util",
-		fmt.Sprintf("%.1f%%", status.EventLoop.Utilization),
-		utilizationStatus,
-		"< 70%",
-	}, []tablewriter.Colors{{}, utilizationColor, utilizationColor, {}})
-
 	// GC metrics
-	gcStatus := "✅ Normal"
-	gcColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if status.GC.Duration > 10 {
-		gcStatus = "⚠️  High"
-		gcColor = tablewriter.Colors{tablewriter.FgYellowColor}
-	}
-	if status.GC.Duration > 50 {
-		gcStatus = "🚨 Critical"
-		gcColor = tablewriter.Colors{tablewriter.FgRedColor}
+	if d.shows("gc") {
+		gcStatus, gcColor := d.statusCell(status.GC.Duration, d.thresholds.GC.Warning, d.thresholds.GC.Critical)
+		row([]string{
+			"GC Duration",
+			fmt.Sprintf("%.2f ms (%s)", status.GC.Duration, status.GC.Type),
+			gcStatus,
+			fmt.Sprintf("< %g ms", d.thresholds.GC.Warning),
+		}, []tablewriter.Colors{{}, d.gradientColor(status.GC.Duration / d.thresholds.GC.Warning), gcColor, {}},
+			func(s *types.Status) float64 { return s.GC.Duration })
 	}
 
-	table.Rich([]string{
-		"GC Duration",
-		fmt.Sprintf("%.2f ms (%s)", status.GC.Duration, status.GC.Type),
-		gcStatus,
-		"< 10 ms",
-	}, []tablewriter.Colors{{}, gcColor, gcColor, {}})
-
 	// Handle metrics
-	handleStatus := "✅ Normal"
-	handleColor := tablewriter.Colors{tablewriter.FgGreenColor}
-	if status.Handles.Active > 50 {
-		handleStatus = "⚠️  High"
-		handleColor = tablewriter.Colors{tablewriter.FgYellowColor}
+	if d.shows("handles") {
+		handleStatus, handleColor := d.statusCell(float64(status.Handles.Active), d.thresholds.Handles.Warning, d.thresholds.Handles.Critical)
+		row([]string{
+			"Active Handles",
+			fmt.Sprintf("%d (T:%d, S:%d)", status.Handles.Active, status.Handles.Timers, status.Handles.TCPSockets),
+			handleStatus,
+			fmt.Sprintf("< %.0f", d.thresholds.Handles.Warning),
+		}, []tablewriter.Colors{{}, d.gradientColor(float64(status.Handles.Active) / d.thresholds.Handles.Warning), handleColor, {}},
+			func(s *types.Status) float64 { return float64(s.Handles.Active) })
 	}
-	if status.Handles.Active > 100 {
-		handleStatus = "🚨 Critical"
-		handleColor = tablewriter.Colors{tablewriter.FgRedColor}
-	}
-
-	table.Rich([]string{
-		"Active Handles",
-		fmt.Sprintf("%d (T:%d, S:%d)", status.Handles.Active, status.Handles.Timers, status.Handles.TCPSockets),
-		handleStatus,
-		"< 50",
-	}, []tablewriter.Colors{{}, handleColor, handleColor, {}})
 
 	table.Render()
-	fmt.Println()
+	fmt.Fprintln(d.out)
 
 	// Display additional metrics in a second table
 	d.displayAdvancedMetrics(status)
 }
 
+// memoryText is the current value of the memory row: the RSS, followed by
+// the swapped-out size while any of the process is swapped out, since
+// heavy swapping slows it down more than the RSS shows.
+func memoryText(memory types.MemoryMetrics, units UnitMode) string {
+	if memory.Swap == 0 {
+		return FormatBytes(memory.RSS, units)
+	}
+	return fmt.Sprintf("%s (+%s swap)", FormatBytes(memory.RSS, units), FormatBytes(memory.Swap, units))
+}
+
+// statusCell returns the Status column of a metric at value: normal, high
+// above warning or critical above critical, in the theme's state color.
+func (d *Dashboard) statusCell(value, warning, critical float64) (string, tablewriter.Colors) {
+	switch {
+	case value > critical:
+		return d.glyphs.Critical + " Critical", cell(d.theme.Critical)
+	case value > warning:
+		return d.glyphs.Warning + " High", cell(d.theme.Warning)
+	default:
+		return d.glyphs.OK + " Normal", cell(d.theme.OK)
+	}
+}
+
+// gradientColor maps how close a metric is to its threshold (value divided
+// by threshold) onto the theme's gradient: its first color below half, then
+// shading through to the last from the threshold onwards.
+func (d *Dashboard) gradientColor(ratio float64) tablewriter.Colors {
+	step := 0
+	switch {
+	case ratio >= 1:
+		step = 5
+	case ratio >= 0.9:
+		step = 4
+	case ratio >= 0.75:
+		step = 3
+	case ratio >= 0.6:
+		step = 2
+	case ratio >= 0.5:
+		step = 1
+	}
+	return cell(d.theme.Gradient[step])
+}
+
 func (d *Dashboard) displayAdvancedMetrics(status *types.Status) {
-	advancedColor := color.New(color.FgMagenta, color.Bold)
-	advancedColor.Println("📊 Advanced Node.js Metrics:")
+	advancedColor := text(d.theme.Section)
+	advancedColor.Fprintln(d.out, "📊 Advanced Node.js Metrics:")
 
 	// Create advanced metrics table
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(d.out)
 	table.SetHeader([]string{"Metric", "Current", "Details"})
 	table.SetBorder(true)
 	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgMagentaColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgMagentaColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgMagentaColor},
+		cell(d.theme.Section),
+		cell(d.theme.Section),
+		cell(d.theme.Section),
 	)
 
 	// Event loop statistics
-	table.Append([]string{
-		"Event Loop Stats",
-		fmt.Sprintf("Avg: %.2fms", status.EventLoop.Mean),
-		fmt.Sprintf("Min: %.2f, Max: %.2f, P95: %.2f", 
-			status.EventLoop.Min, status.EventLoop.Max, status.EventLoop.P95),
-	})
-
-	// Thread pool
-	table.Append([]string{
-		"Thread Pool",
-		fmt.Sprintf("Active: %d/%d", status.ThreadPool.ActiveCount, status.ThreadPool.PoolSize),
-		fmt.Sprintf("Queue: %d, Pending: %d", 
-			status.ThreadPool.QueueSize, status.ThreadPool.PendingCount),
-	})
+	if d.shows("eventloop") {
+		table.Append([]string{
+			"Event Loop Stats",
+			fmt.Sprintf("Avg: %.2fms", status.EventLoop.Mean),
+			fmt.Sprintf("Min: %.2f, Max: %.2f, P95: %.2f",
+				status.EventLoop.Min, status.EventLoop.Max, status.EventLoop.P95),
+		})
+	}
+
+	// Thread pool; estimated counts are marked with ~
+	if d.shows("threadpool") {
+		estimate := ""
+		if status.ThreadPool.Estimated {
+			estimate = "~"
+		}
+		table.Append([]string{
+			"Thread Pool",
+			fmt.Sprintf("Active: %s%d/%d", estimate, status.ThreadPool.ActiveCount, status.ThreadPool.PoolSize),
+			fmt.Sprintf("Queue: %s%d, Pending: %s%d",
+				estimate, status.ThreadPool.QueueSize, estimate, status.ThreadPool.PendingCount),
+		})
+	}
+
+	// Process memory beyond the RSS, where the platform reports it
+	if status.Memory.Extended {
+		table.Append([]string{
+			"Process Memory",
+			fmt.Sprintf("Swap: %s", FormatBytes(status.Memory.Swap, d.units)),
+			fmt.Sprintf("Shared: %s, Virtual: %s",
+				FormatBytes(status.Memory.Shared, d.units),
+				FormatBytes(status.Memory.VMS, d.units)),
+		})
+	}
+
+	// File descriptors against RLIMIT_NOFILE
+	if usage, ok := types.FDUsagePercent(status.Handles); ok && d.shows("handles") {
+		table.Append([]string{
+			"File Descriptors",
+			fmt.Sprintf("%d/%d (%.1f%%)", status.Handles.FDs, status.Handles.FDLimit, usage),
+			fmt.Sprintf("Hard limit: %d", status.Handles.FDHardLimit),
+		})
+	}
 
 	// GC statistics
-	table.Append([]string{
-		"Garbage Collection",
-		fmt.Sprintf("Collections: %d", status.GC.Collections),
-		fmt.Sprintf("Total: %d (%.2fms), Reason: %s", 
-			status.GC.CollectionsTotal, status.GC.DurationTotal, status.GC.Reason),
-	})
+	if d.shows("gc") {
+		table.Append([]string{
+			"Garbage Collection",
+			fmt.Sprintf("Collections: %d", status.GC.Collections),
+			fmt.Sprintf("Total: %d (%.2fms), Reason: %s, Reclaim: %.1f%%",
+				status.GC.CollectionsTotal, status.GC.DurationTotal, status.GC.Reason,
+				status.GC.ReclaimEfficiency*100),
+		})
+	}
 
 	// V8 heap spaces
-	if len(status.V8.HeapSpaceUsed) > 0 {
+	if len(status.V8.HeapSpaceUsed) > 0 && d.shows("v8") {
 		var heapDetails []string
-		for space, used := range status.V8.HeapSpaceUsed {
-			heapDetails = append(heapDetails, fmt.Sprintf("%s: %.1fMB", 
-				space, float64(used)/1024/1024))
+		for _, space := range orderedHeapSpaces(status.V8.HeapSpaceUsed) {
+			heapDetails = append(heapDetails, fmt.Sprintf("%s: %s",
+				space, FormatBytes(status.V8.HeapSpaceUsed[space], d.units)))
 		}
 		table.Append([]string{
 			"V8 Heap Spaces",
@@ -263,36 +542,230 @@ func (d *Dashboard) displayAdvancedMetrics(status *types.Status) {
 		})
 	}
 
-	// Memory details
-	table.Append([]string{
-		"Memory Details",
-		fmt.Sprintf("Malloc: %.1fMB", float64(status.V8.MallocedMemory)/1024/1024),
-		fmt.Sprintf("Peak: %.1fMB, External: %.1fMB", 
-			float64(status.V8.PeakMallocedMemory)/1024/1024,
-			float64(status.Memory.External)/1024/1024),
-	})
+	// V8 deoptimized frames (only sampled with --compare-runtime)
+	if status.V8.CodeSize > 0 && d.shows("v8") {
+		reason := status.V8.TopDeoptReason
+		if reason == "" {
+			reason = "none"
+		}
+		table.Append([]string{
+			"V8 Deopt Frames",
+			fmt.Sprintf("%d (%.1f/s)", status.V8.DeoptCount, status.V8.DeoptRate),
+			fmt.Sprintf("Top: %s, Code: %s, Bytecode: %s",
+				reason,
+				FormatBytes(status.V8.CodeSize, d.units),
+				FormatBytes(status.V8.BytecodeSize, d.units)),
+		})
+	}
+
+	// Network throughput (--network)
+	if status.Network != nil {
+		table.Append([]string{
+			"Network",
+			fmt.Sprintf("RX: %s, TX: %s", formatRate(status.Network.RxRate, d.units), formatRate(status.Network.TxRate, d.units)),
+			fmt.Sprintf("Total RX: %s, TX: %s",
+				FormatBytes(status.Network.BytesRecv, d.units),
+				FormatBytes(status.Network.BytesSent, d.units)),
+		})
+	}
+
+	// Scheduling priority and OOM-kill likelihood
+	if status.Scheduling != nil {
+		oom := "n/a"
+		if status.Scheduling.OOMScore >= 0 {
+			oom = fmt.Sprintf("%d (adj: %d)", status.Scheduling.OOMScore, status.Scheduling.OOMScoreAdj)
+		}
+		table.Append([]string{
+			"Scheduling",
+			fmt.Sprintf("Nice: %d", status.Scheduling.Nice),
+			"OOM score: " + oom,
+		})
+	}
+
+	// Tracked constructors (--track-constructor)
+	for _, ctor := range status.Constructors {
+		table.Append([]string{
+			"Tracked: " + ctor.Name,
+			fmt.Sprintf("%d instances", ctor.Count),
+			fmt.Sprintf("Retained: %s (%+.1f%%), Self: %s",
+				FormatBytes(uint64(ctor.RetainedSize), d.units),
+				ctor.GrowthPercent,
+				FormatBytes(uint64(ctor.SelfSize), d.units)),
+		})
+	}
+
+	// Custom metrics (--custom-metric)
+	if len(status.Custom) > 0 {
+		names := make([]string, 0, len(status.Custom))
+		for name := range status.Custom {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var customDetails []string
+		for _, name := range names {
+			customDetails = append(customDetails, fmt.Sprintf("%s: %g", name, status.Custom[name]))
+		}
+		table.Append([]string{
+			"Custom Metrics",
+			fmt.Sprintf("%d metrics", len(names)),
+			strings.Join(customDetails, ", "),
+		})
+	}
+
+	// Memory details; malloc figures come from the V8 group
+	if d.shows("v8") {
+		table.Append([]string{
+			"Memory Details",
+			"Malloc: " + FormatBytes(status.V8.MallocedMemory, d.units),
+			fmt.Sprintf("Peak: %s, External: %s",
+				FormatBytes(status.V8.PeakMallocedMemory, d.units),
+				FormatBytes(status.Memory.External, d.units)),
+		})
+	}
 
 	table.Render()
-	fmt.Println()
+	fmt.Fprintln(d.out)
+}
+
+// V8 heap spaces in display order, roughly from youngest to oldest
+var heapSpaceOrder = []string{
+	"new_space",
+	"old_space",
+	"code_space",
+	"map_space",
+	"shared_space",
+	"trusted_space",
+	"large_object_space",
+	"code_large_object_space",
+	"new_large_object_space",
+	"shared_large_object_space",
+	"read_only_space",
+}
+
+// orderedHeapSpaces returns the space names of spaces in heapSpaceOrder,
+// followed by any unknown spaces sorted by name.
+func orderedHeapSpaces(spaces map[string]uint64) []string {
+	names := make([]string, 0, len(spaces))
+	known := make(map[string]bool, len(heapSpaceOrder))
+	for _, space := range heapSpaceOrder {
+		known[space] = true
+		if _, ok := spaces[space]; ok {
+			names = append(names, space)
+		}
+	}
+
+	var unknown []string
+	for space := range spaces {
+		if !known[space] {
+			unknown = append(unknown, space)
+		}
+	}
+	sort.Strings(unknown)
+	return append(names, unknown...)
+}
+
+// cpuUsageText shows CPU usage on the threshold scale, followed by the other
+// scale when the core count is known. Usage is not known yet on the first
+// sample after attaching to a process.
+func cpuUsageText(cpu types.CPUMetrics) string {
+	if cpu.FirstSample {
+		return "measuring..."
+	}
+	if cpu.Smoothed {
+		return fmt.Sprintf("%.2f%% smoothed (%.2f%% now)", cpu.Percent(), cpu.InstantPercent())
+	}
+	switch {
+	case cpu.Cores == 0:
+		return fmt.Sprintf("%.2f%%", cpu.Usage)
+	case cpu.Normalized:
+		return fmt.Sprintf("%.2f%% (%.2f%% raw)", cpu.NormalizedUsage, cpu.Usage)
+	default:
+		return fmt.Sprintf("%.2f%% (%.2f%% of %d cores)", cpu.Usage, cpu.NormalizedUsage, cpu.Cores)
+	}
+}
+
+// cpuLabel names the CPU row after the scale of its usage: 100% is one
+// core, or every core when the usage was normalized to the machine.
+func cpuLabel(cpu types.CPUMetrics) string {
+	if cpu.Normalized {
+		return "CPU Usage (machine)"
+	}
+	return "CPU Usage (per core)"
+}
+
+// formatRate renders a bytes-per-second rate in the largest fitting unit:
+// decimal for UnitsSI, binary otherwise. Rates always scale, as a fixed
+// unit would show slow links as zero.
+func formatRate(bytesPerSec float64, mode UnitMode) string {
+	if mode == UnitsSI {
+		return scaleBytes(bytesPerSec, 1000, []string{"B", "kB", "MB", "GB"}) + "/s"
+	}
+	return scaleBytes(bytesPerSec, 1024, []string{"B", "KiB", "MiB", "GiB"}) + "/s"
+}
+
+// displayAlertHistory lists recent alert transitions, newest first.
+func (d *Dashboard) displayAlertHistory(events []types.AlertEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	historyColor := text(d.theme.Header)
+	historyColor.Fprintln(d.out, title(d.glyphs.History, "Recent Alert Events:"))
+
+	firedColor := text(d.theme.Critical)
+	resolvedColor := text(d.theme.OK)
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		label, labelColor := "FIRED   ", firedColor
+		if event.Resolved {
+			label, labelColor = "RESOLVED", resolvedColor
+		}
+		fmt.Fprintf(d.out, "  %s  ", event.Timestamp.Format("15:04:05"))
+		labelColor.Fprint(d.out, label)
+		fmt.Fprintf(d.out, "  [%s] %s\n", event.Alert.Severity, event.Alert.Message)
+	}
+	fmt.Fprintln(d.out)
+}
+
+// displayAnnotations lists recent markers, comparing the sample taken just
+// before each marker with the current one.
+func (d *Dashboard) displayAnnotations(status *types.Status) {
+	if len(status.Annotations) == 0 {
+		return
+	}
+
+	markerColor := text(d.theme.Section)
+	markerColor.Fprintln(d.out, title(d.glyphs.Markers, "Markers:"))
+
+	for _, annotation := range status.Annotations {
+		fmt.Fprintf(d.out, "  %s  %-24s CPU %.1f%% → %.1f%%  RSS %s → %s  Lag %.2f → %.2f ms\n",
+			annotation.Timestamp.Format("15:04:05"),
+			annotation.Text,
+			annotation.CPUBefore, status.CPU.Percent(),
+			FormatBytes(annotation.RSSBefore, d.units), FormatBytes(status.Memory.RSS, d.units),
+			annotation.LagBefore, status.EventLoop.Lag)
+	}
+	fmt.Fprintln(d.out)
 }
 
 func (d *Dashboard) displayAlerts(alerts []types.Alert) {
 	if len(alerts) == 0 {
-		successColor := color.New(color.FgGreen)
-		successColor.Println("✅ No active alerts")
+		successColor := text(d.theme.OK)
+		successColor.Fprintln(d.out, title(d.glyphs.OK, "No active alerts"))
 		return
 	}
 
-	alertColor := color.New(color.FgRed, color.Bold)
-	alertColor.Printf("🚨 Active Alerts (%d):\n", len(alerts))
+	alertColor := text(d.theme.Alert)
+	alertColor.Fprintf(d.out, "%s (%d):\n", title(d.glyphs.Critical, "Active Alerts"), len(alerts))
 	
 	for i, alert := range alerts {
-		fmt.Printf("  %d. [%s] %s (%.2f > %.2f)\n", 
+		fmt.Fprintf(d.out, "  %d. [%s] %s (%.2f > %.2f)\n", 
 			i+1, 
 			string(alert.Severity), 
 			alert.Message, 
 			alert.Value, 
 			alert.Threshold)
 	}
-	fmt.Println()
+	fmt.Fprintln(d.out)
 }
\ No newline at end of file
@@ -0,0 +1,149 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"stackpulse/internal/types"
+)
+
+// FleetEntry is one service in the aggregated fleet view. Status is the
+// last good snapshot and may be stale when Err is set.
+type FleetEntry struct {
+	Name     string
+	Status   *types.Status
+	Err      error
+	LastSeen time.Time
+}
+
+// FleetDashboard renders one row per service plus the combined alerts. On a
+// non-terminal output it prints plain lines instead.
+type FleetDashboard struct {
+	dashboard *Dashboard
+	out       io.Writer
+	plain     bool
+}
+
+func NewFleetDashboard() *FleetDashboard {
+	return &FleetDashboard{
+		dashboard: NewDashboard(),
+		out:       os.Stdout,
+		plain:     !IsTerminal(os.Stdout),
+	}
+}
+
+// SetGlyphs changes the state indicators.
+func (f *FleetDashboard) SetGlyphs(glyphs Glyphs) {
+	f.dashboard.SetGlyphs(glyphs)
+}
+
+// SetUnits sets the unit of the memory column.
+func (f *FleetDashboard) SetUnits(mode UnitMode) {
+	f.dashboard.SetUnits(mode)
+}
+
+// SetTheme changes the palette.
+func (f *FleetDashboard) SetTheme(theme Theme) {
+	f.dashboard.SetTheme(theme)
+}
+
+func (f *FleetDashboard) Update(entries []FleetEntry, alerts []types.Alert) {
+	if f.plain {
+		f.printLines(entries, alerts)
+		return
+	}
+
+	f.dashboard.clearScreen()
+	headerColor := text(f.dashboard.theme.Header)
+	headerColor.Println("╔══════════════════════════════════════════════════════════════════════════════╗")
+	headerColor.Println("║                         STACKPULSE FLEET DASHBOARD                           ║")
+	headerColor.Println("╚══════════════════════════════════════════════════════════════════════════════╝")
+	fmt.Printf("Last Update: %s\n\n", time.Now().Format("15:04:05.000"))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Service", "State", "PID", "CPU", "Memory", "Heap", "Lag", "Alerts"})
+	table.SetBorder(true)
+
+	for _, entry := range entries {
+		state := f.dashboard.glyphs.OK + " Up"
+		stateColor := cell(f.dashboard.theme.OK)
+		if entry.Err != nil {
+			state = f.dashboard.glyphs.Critical + " Down"
+			stateColor = cell(f.dashboard.theme.Critical)
+		}
+
+		if entry.Status == nil {
+			table.Rich([]string{entry.Name, state, "-", "-", "-", "-", "-", "-"},
+				[]tablewriter.Colors{{}, stateColor})
+			continue
+		}
+
+		status := entry.Status
+		heap := "-"
+		if heapUsage, ok := types.HeapUsagePercent(status.Memory); ok {
+			heap = fmt.Sprintf("%.1f%%", heapUsage)
+		}
+		alertColor := cell(f.dashboard.theme.OK)
+		if worst := worstSeverity(status.Alerts); worst.Rank() >= types.SeverityCritical.Rank() {
+			alertColor = cell(f.dashboard.theme.Critical)
+		} else if worst.Rank() > 0 {
+			alertColor = cell(f.dashboard.theme.Warning)
+		}
+
+		table.Rich([]string{
+			entry.Name,
+			state,
+			fmt.Sprintf("%d", status.PID),
+			fmt.Sprintf("%.1f%%", status.CPU.Percent()),
+			FormatBytes(status.Memory.RSS, f.dashboard.units),
+			heap,
+			fmt.Sprintf("%.2f ms", status.EventLoop.Lag),
+			fmt.Sprintf("%d", len(status.Alerts)),
+		}, []tablewriter.Colors{{}, stateColor, {}, {}, {}, {}, {}, alertColor})
+	}
+
+	table.Render()
+	fmt.Println()
+	f.dashboard.displayAlerts(alerts)
+}
+
+func (f *FleetDashboard) printLines(entries []FleetEntry, alerts []types.Alert) {
+	now := time.Now().Format("2006-01-02T15:04:05.000")
+	for _, entry := range entries {
+		fields := []string{now, "service=" + entry.Name}
+		if entry.Err != nil {
+			fields = append(fields, "state=down")
+		} else {
+			fields = append(fields, "state=up")
+		}
+		if entry.Status != nil {
+			fields = append(fields,
+				fmt.Sprintf("cpu=%.2f%%", entry.Status.CPU.Percent()),
+				fmt.Sprintf("rss=%.1fMB", float64(entry.Status.Memory.RSS)/1024/1024),
+				fmt.Sprintf("lag=%.2fms", entry.Status.EventLoop.Lag),
+				fmt.Sprintf("alerts=%d", len(entry.Status.Alerts)),
+			)
+		}
+		fmt.Fprintln(f.out, strings.Join(fields, " "))
+	}
+	for _, alert := range alerts {
+		fmt.Fprintf(f.out, "%s ALERT [%s] %s\n",
+			alert.Timestamp.Format(time.RFC3339), alert.Severity, alert.Message)
+	}
+}
+
+// worstSeverity returns the most severe severity among alerts, or "" when
+// there are none.
+func worstSeverity(alerts []types.Alert) types.AlertSeverity {
+	var worst types.AlertSeverity
+	for _, alert := range alerts {
+		if alert.Severity.Rank() > worst.Rank() {
+			worst = alert.Severity
+		}
+	}
+	return worst
+}
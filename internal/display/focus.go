@@ -0,0 +1,241 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"stackpulse/internal/types"
+)
+
+// Size of the chart in the focus view
+const (
+	focusChartWidth  = 100
+	focusChartHeight = 10
+)
+
+// chartBlocks are the partial cells of a chart column, in eighths
+var chartBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// focusGroup is a metric group that can be expanded to the whole screen:
+// a chart of one headline value over the recent history plus details. A
+// unit of "bytes" charts the value in the dashboard's byte unit.
+type focusGroup struct {
+	key     byte
+	title   string
+	unit    string
+	value   func(status *types.Status) float64
+	details func(d *Dashboard, status *types.Status)
+}
+
+var focusGroups = []focusGroup{
+	{'1', "CPU Usage", "%", func(s *types.Status) float64 { return s.CPU.Percent() }, (*Dashboard).displayCPUDetails},
+	{'2', "Memory (RSS)", "bytes", func(s *types.Status) float64 { return float64(s.Memory.RSS) }, (*Dashboard).displayMemoryDetails},
+	{'3', "V8 Heap Used", "bytes", func(s *types.Status) float64 { return float64(s.Memory.HeapUsed) }, (*Dashboard).displayHeapSpaceDetails},
+	{'4', "Event Loop Lag", "ms", func(s *types.Status) float64 { return s.EventLoop.Lag }, (*Dashboard).displayEventLoopDetails},
+	{'5', "GC Duration", "ms", func(s *types.Status) float64 { return s.GC.Duration }, (*Dashboard).displayGCDetails},
+	{'6', "Active Handles", "", func(s *types.Status) float64 { return float64(s.Handles.Active) }, (*Dashboard).displayHandleDetails},
+}
+
+// HandleKey switches the dashboard between the overview (0 or Esc) and the
+// focus view of one metric group (1-6, Tab cycles), redrawing at once.
+func (d *Dashboard) HandleKey(key byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	focus := d.focus
+	switch key {
+	case '0', 0x1b:
+		focus = -1
+	case '\t':
+		focus = (focus + 1) % len(focusGroups)
+	default:
+		for i, group := range focusGroups {
+			if group.key == key {
+				focus = i
+			}
+		}
+	}
+	if focus == d.focus {
+		return
+	}
+
+	d.focus = focus
+	if d.lastRendered != nil {
+		d.render(d.lastRendered)
+	}
+}
+
+func (d *Dashboard) displayFocus(status *types.Status) {
+	group := focusGroups[d.focus]
+	history := d.recentHistory(status, focusChartWidth)
+
+	unit, divisor := group.unit, 1.0
+	if unit == "bytes" {
+		divisor, unit = chartUnit(d.units)
+	}
+
+	values := make([]float64, len(history))
+	min, max, sum := math.Inf(1), math.Inf(-1), 0.0
+	for i := range history {
+		values[i] = group.value(&history[i]) / divisor
+		min = math.Min(min, values[i])
+		max = math.Max(max, values[i])
+		sum += values[i]
+	}
+
+	titleColor := text(d.theme.Title)
+	titleColor.Fprintf(d.out, "%s (PID %d)\n\n", group.title, status.PID)
+
+	for _, row := range chart(values, focusChartHeight) {
+		fmt.Fprintln(d.out, row)
+	}
+	fmt.Fprintf(d.out, "\nNow: %.2f%s  Min: %.2f%s  Avg: %.2f%s  Max: %.2f%s  (last %d samples)\n\n",
+		values[len(values)-1], unit, min, unit,
+		sum/float64(len(values)), unit, max, unit, len(values))
+
+	group.details(d, status)
+	fmt.Fprintln(d.out)
+}
+
+// recentHistory returns up to n of the latest samples from the history
+// source, ending with status.
+func (d *Dashboard) recentHistory(status *types.Status, n int) []types.Status {
+	var history []types.Status
+	if d.history != nil {
+		history = d.history()
+	}
+	if len(history) == 0 || history[len(history)-1].Timestamp != status.Timestamp {
+		history = append(history, *status)
+	}
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	return history
+}
+
+// chart draws values as columns height rows tall, oldest on the left and
+// scaled from zero to the largest value, with the scale on the left.
+func chart(values []float64, height int) []string {
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+
+	rows := make([]string, height)
+	for row := range rows {
+		level := float64(height - 1 - row)
+		label := ""
+		switch row {
+		case 0:
+			label = fmt.Sprintf("%.1f", max)
+		case height - 1:
+			label = "0"
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%9s │", label)
+		for _, v := range values {
+			filled := 0.0
+			if max > 0 {
+				filled = v / max * float64(height)
+			}
+			switch remain := filled - level; {
+			case remain >= 1:
+				b.WriteRune(chartBlocks[len(chartBlocks)-1])
+			case remain <= 0:
+				b.WriteRune(' ')
+			default:
+				b.WriteRune(chartBlocks[int(remain*float64(len(chartBlocks)-1))])
+			}
+		}
+		rows[row] = b.String()
+	}
+	return rows
+}
+
+func (d *Dashboard) detailTable(header ...string) *tablewriter.Table {
+	table := tablewriter.NewWriter(d.out)
+	table.SetHeader(header)
+	table.SetBorder(true)
+	return table
+}
+
+func (d *Dashboard) displayCPUDetails(status *types.Status) {
+	table := d.detailTable("Metric", "Value")
+	table.Append([]string{cpuLabel(status.CPU), cpuUsageText(status.CPU)})
+	if status.CPU.Cores > 0 {
+		table.Append([]string{"Logical CPUs", fmt.Sprintf("%d", status.CPU.Cores)})
+	}
+	table.Append([]string{"User time", fmt.Sprintf("%.2fs", status.CPU.UserTime)})
+	table.Append([]string{"System time", fmt.Sprintf("%.2fs", status.CPU.SystemTime)})
+	table.Render()
+}
+
+func (d *Dashboard) displayMemoryDetails(status *types.Status) {
+	table := d.detailTable("Metric", "Value")
+	table.Append([]string{"RSS", FormatBytes(status.Memory.RSS, d.units)})
+	table.Append([]string{"Heap used", FormatBytes(status.Memory.HeapUsed, d.units)})
+	table.Append([]string{"Heap total", FormatBytes(status.Memory.HeapTotal, d.units)})
+	table.Append([]string{"External", FormatBytes(status.Memory.External, d.units)})
+	table.Render()
+}
+
+func (d *Dashboard) displayHeapSpaceDetails(status *types.Status) {
+	if len(status.V8.HeapSpaceUsed) == 0 {
+		fmt.Fprintln(d.out, "No V8 heap space data (is the inspector reachable?)")
+		return
+	}
+
+	table := d.detailTable("Space", "Used", "Size", "Used %")
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, space := range orderedHeapSpaces(status.V8.HeapSpaceUsed) {
+		used, size := status.V8.HeapSpaceUsed[space], status.V8.HeapSpaceSize[space]
+		percent := "-"
+		if size > 0 {
+			percent = fmt.Sprintf("%.1f%%", float64(used)/float64(size)*100)
+		}
+		table.Append([]string{
+			space,
+			FormatBytes(used, d.units),
+			FormatBytes(size, d.units),
+			percent,
+		})
+	}
+	table.Render()
+}
+
+func (d *Dashboard) displayEventLoopDetails(status *types.Status) {
+	table := d.detailTable("Metric", "Value")
+	table.Append([]string{"Mean", fmt.Sprintf("%.2f ms", status.EventLoop.Mean)})
+	table.Append([]string{"Min", fmt.Sprintf("%.2f ms", status.EventLoop.Min)})
+	table.Append([]string{"P95", fmt.Sprintf("%.2f ms", status.EventLoop.P95)})
+	table.Append([]string{"Max", fmt.Sprintf("%.2f ms", status.EventLoop.Max)})
+	table.Append([]string{"Utilization", fmt.Sprintf("%.1f%%", status.EventLoop.Utilization)})
+	table.Render()
+}
+
+func (d *Dashboard) displayGCDetails(status *types.Status) {
+	table := d.detailTable("Metric", "Value")
+	table.Append([]string{"Collections (last poll)", fmt.Sprintf("%d", status.GC.Collections)})
+	table.Append([]string{"Latest", fmt.Sprintf("%s (%s)", status.GC.Type, status.GC.Reason)})
+	table.Append([]string{"Heap before/after", fmt.Sprintf("%s / %s",
+		FormatBytes(status.GC.HeapSizeBefore, d.units), FormatBytes(status.GC.HeapSizeAfter, d.units))})
+	table.Append([]string{"Reclaimed", fmt.Sprintf("%.1f%%", status.GC.ReclaimEfficiency*100)})
+	table.Append([]string{"Total", fmt.Sprintf("%d (%.2f ms)", status.GC.CollectionsTotal, status.GC.DurationTotal)})
+	table.Render()
+}
+
+func (d *Dashboard) displayHandleDetails(status *types.Status) {
+	table := d.detailTable("Kind", "Count")
+	table.Append([]string{"Timers", fmt.Sprintf("%d", status.Handles.Timers)})
+	table.Append([]string{"TCP sockets", fmt.Sprintf("%d", status.Handles.TCPSockets)})
+	table.Append([]string{"UDP sockets", fmt.Sprintf("%d", status.Handles.UDPSockets)})
+	table.Append([]string{"Files", fmt.Sprintf("%d", status.Handles.Files)})
+	table.Append([]string{"Refs", fmt.Sprintf("%d", status.Handles.Refs)})
+	if status.Handles.FDLimit > 0 {
+		table.Append([]string{"File descriptors", fmt.Sprintf("%d/%d", status.Handles.FDs, status.Handles.FDLimit)})
+	}
+	table.Render()
+}
@@ -0,0 +1,58 @@
+package display
+
+// Glyphs are the indicators drawn next to metric states and section titles.
+// Every set keeps a fixed display width per field, as the table column
+// sizing counts runes rather than what the terminal actually draws.
+type Glyphs struct {
+	OK       string
+	Warning  string
+	Critical string
+
+	// Section titles; empty drops the icon
+	Monitor string
+	Markers string
+	History string
+}
+
+var glyphSets = map[string]Glyphs{
+	// Emoji with default emoji presentation are two cells wide everywhere,
+	// unlike ⚠️ which many terminals draw as a single cell
+	"emoji": {
+		OK:       "✅",
+		Warning:  "🔶",
+		Critical: "🚨",
+		Monitor:  "🔍",
+		Markers:  "📌",
+		History:  "🕘",
+	},
+	"unicode": {
+		OK:       "✔",
+		Warning:  "▲",
+		Critical: "✖",
+		Monitor:  "»",
+		Markers:  "»",
+		History:  "»",
+	},
+	"ascii": {
+		OK:       "+",
+		Warning:  "!",
+		Critical: "x",
+	},
+}
+
+// LookupGlyphs returns the named glyph set; an empty name is the emoji set.
+func LookupGlyphs(name string) (Glyphs, bool) {
+	if name == "" {
+		name = "emoji"
+	}
+	glyphs, ok := glyphSets[name]
+	return glyphs, ok
+}
+
+// title prefixes text with icon when the set has one.
+func title(icon, text string) string {
+	if icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
@@ -0,0 +1,37 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"stackpulse/internal/types"
+)
+
+// PrintHeapDiff writes the constructors that grew between two heap
+// snapshots as a table.
+func PrintHeapDiff(out io.Writer, diffs []types.ConstructorDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(out, "No constructor grew between the snapshots")
+		return
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Constructor", "Instances", "Δ Instances", "Retained", "Δ Retained"})
+	table.SetBorder(true)
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+	})
+
+	for _, diff := range diffs {
+		table.Append([]string{
+			diff.Name,
+			fmt.Sprintf("%d → %d", diff.CountBefore, diff.CountAfter),
+			fmt.Sprintf("%+d", diff.CountDelta()),
+			fmt.Sprintf("%.1f → %.1f KB", float64(diff.RetainedBefore)/1024, float64(diff.RetainedAfter)/1024),
+			fmt.Sprintf("%+.1f KB", float64(diff.RetainedDelta())/1024),
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,28 @@
+package display
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"stackpulse/internal/export"
+	"stackpulse/internal/types"
+)
+
+// InfluxRenderer writes every status as an InfluxDB line protocol point
+// tagged with this host, for telegraf to read from stdout.
+type InfluxRenderer struct {
+	out  io.Writer
+	host string
+}
+
+func NewInfluxRenderer(out io.Writer) *InfluxRenderer {
+	host, _ := os.Hostname()
+	return &InfluxRenderer{out: out, host: host}
+}
+
+func (r *InfluxRenderer) Update(status *types.Status) {
+	if err := export.WriteInflux(r.out, status, r.host); err != nil {
+		log.Printf("Warning: Failed to write status: %v", err)
+	}
+}
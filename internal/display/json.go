@@ -0,0 +1,36 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"stackpulse/internal/export"
+	"stackpulse/internal/types"
+)
+
+// JSONRenderer writes every status as one JSON object per line, for piping
+// into jq or a log pipeline. Each object carries export.SchemaVersion.
+type JSONRenderer struct {
+	encoder *json.Encoder
+}
+
+func NewJSONRenderer(out io.Writer) *JSONRenderer {
+	return &JSONRenderer{encoder: json.NewEncoder(out)}
+}
+
+// SetPretty indents each status over several lines, for reading by hand;
+// the output is then no longer one status per line.
+func (j *JSONRenderer) SetPretty(pretty bool) {
+	if pretty {
+		j.encoder.SetIndent("", "  ")
+	} else {
+		j.encoder.SetIndent("", "")
+	}
+}
+
+func (j *JSONRenderer) Update(status *types.Status) {
+	if err := j.encoder.Encode(export.NewStatusEnvelope(status)); err != nil {
+		log.Printf("Warning: Failed to write status: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package display
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ListenKeys switches the terminal on in to unbuffered input without echo
+// and passes every key press to HandleKey until ctx ends. Ctrl+C still
+// interrupts. The returned function restores the terminal.
+func (d *Dashboard) ListenKeys(ctx context.Context, in *os.File) (func(), error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("dashboard keys are not supported on windows")
+	}
+
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal settings: %w", err)
+	}
+	if _, err := stty(in, "-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("failed to set terminal input mode: %w", err)
+	}
+
+	d.mu.Lock()
+	d.keys = true
+	d.mu.Unlock()
+
+	go func() {
+		buf := make([]byte, 16)
+		for ctx.Err() == nil {
+			n, err := in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range buf[:n] {
+				d.HandleKey(key)
+			}
+		}
+	}()
+
+	return func() {
+		stty(in, strings.TrimSpace(saved))
+	}, nil
+}
+
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	return string(out), err
+}
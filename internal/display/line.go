@@ -0,0 +1,157 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Renderer presents each new status snapshot to the user.
+type Renderer interface {
+	Update(status *types.Status)
+}
+
+// LineRenderer writes one plain-text line per update, without colors or
+// screen clearing, for output redirected to files and pipes.
+type LineRenderer struct {
+	out io.Writer
+
+	// Timestamps of the newest annotation and resolved alert already written
+	lastMarker   time.Time
+	lastResolved time.Time
+}
+
+func NewLineRenderer(out io.Writer) *LineRenderer {
+	return &LineRenderer{out: out}
+}
+
+func (l *LineRenderer) Update(status *types.Status) {
+	fields := []string{
+		status.Timestamp.Format("2006-01-02T15:04:05.000"),
+		fmt.Sprintf("pid=%d", status.PID),
+		fmt.Sprintf("cpu=%.2f%%", status.CPU.Percent()),
+		fmt.Sprintf("rss=%.1fMB", float64(status.Memory.RSS)/1024/1024),
+	}
+	if status.Memory.Extended {
+		fields = append(fields, fmt.Sprintf("swap=%.1fMB", float64(status.Memory.Swap)/1024/1024))
+	}
+	if heapUsage, ok := types.HeapUsagePercent(status.Memory); ok {
+		fields = append(fields, fmt.Sprintf("heap=%.1f%%", heapUsage))
+	}
+	fields = append(fields,
+		fmt.Sprintf("lag=%.2fms", status.EventLoop.Lag),
+		fmt.Sprintf("elu=%.1f%%", status.EventLoop.Utilization),
+		fmt.Sprintf("gc=%.2fms", status.GC.Duration),
+		fmt.Sprintf("handles=%d", status.Handles.Active),
+	)
+	if status.Network != nil {
+		fields = append(fields,
+			fmt.Sprintf("rx=%.0fB/s", status.Network.RxRate),
+			fmt.Sprintf("tx=%.0fB/s", status.Network.TxRate),
+		)
+	}
+	if !status.InspectorAvailable {
+		fields = append(fields, "inspector=unavailable")
+	}
+	fields = append(fields, fmt.Sprintf("alerts=%d", len(status.Alerts)))
+	fmt.Fprintln(l.out, strings.Join(fields, " "))
+
+	for _, annotation := range status.Annotations {
+		if !annotation.Timestamp.After(l.lastMarker) {
+			continue
+		}
+		l.lastMarker = annotation.Timestamp
+		fmt.Fprintf(l.out, "%s MARK %s (before: cpu=%.2f%% rss=%.1fMB lag=%.2fms)\n",
+			annotation.Timestamp.Format(time.RFC3339), annotation.Text,
+			annotation.CPUBefore, float64(annotation.RSSBefore)/1024/1024, annotation.LagBefore)
+	}
+
+	for _, alert := range status.Alerts {
+		fmt.Fprintf(l.out, "%s ALERT [%s] %s\n",
+			alert.Timestamp.Format(time.RFC3339), alert.Severity, alert.Message)
+	}
+
+	for _, event := range status.AlertEvents {
+		if !event.Resolved || !event.Timestamp.After(l.lastResolved) {
+			continue
+		}
+		l.lastResolved = event.Timestamp
+		fmt.Fprintf(l.out, "%s RESOLVED [%s] %s\n",
+			event.Timestamp.Format(time.RFC3339), event.Alert.Severity, event.Alert.Message)
+	}
+}
+
+// PrintStatus writes a short human-readable summary of status to out.
+func PrintStatus(out io.Writer, status *types.Status) {
+	fmt.Fprintf(out, "PID: %d\n", status.PID)
+	fmt.Fprintf(out, "Sampled: %s (%s ago)\n", status.Timestamp.Format("15:04:05.000"),
+		time.Since(status.Timestamp).Round(time.Millisecond))
+	fmt.Fprintf(out, "CPU Usage: %.2f%%\n", status.CPU.Percent())
+	fmt.Fprintf(out, "Memory Usage: %d MB\n", status.Memory.RSS/1024/1024)
+	if heapUsage, ok := types.HeapUsagePercent(status.Memory); ok {
+		fmt.Fprintf(out, "Heap Usage: %.1f%% (%d of %d MB)\n", heapUsage,
+			status.Memory.HeapUsed/1024/1024, status.Memory.HeapTotal/1024/1024)
+	}
+	fmt.Fprintf(out, "Event Loop Lag: %.2fms (p95 %.2fms)\n", status.EventLoop.Lag, status.EventLoop.P95)
+	fmt.Fprintf(out, "GC: %d collections, %.2fms\n", status.GC.Collections, status.GC.Duration)
+	fmt.Fprintf(out, "Active Handles: %d\n", status.Handles.Active)
+	fmt.Fprintf(out, "Alerts: %d\n", len(status.Alerts))
+	for _, alert := range status.Alerts {
+		fmt.Fprintf(out, "  [%s] %s\n", alert.Severity, alert.Message)
+	}
+}
+
+// PrintSummary writes the report of a monitoring session to out: how long
+// it ran, peak usage, GC totals and the incidents raised.
+func PrintSummary(out io.Writer, summary types.SessionSummary, units UnitMode) {
+	fmt.Fprintf(out, "\nSession Summary\n")
+	fmt.Fprintf(out, "Monitored: %s (%d samples)\n", summary.Duration.Round(time.Second), summary.Samples)
+	fmt.Fprintf(out, "Peak CPU: %.2f%%\n", summary.PeakCPU)
+	fmt.Fprintf(out, "Peak Memory: %s\n", FormatBytes(summary.PeakRSS, units))
+	fmt.Fprintf(out, "Peak Event Loop Lag: %.2fms\n", summary.PeakLag)
+	if summary.GCCollections > 0 {
+		fmt.Fprintf(out, "GC: %d collections, %.2fms average\n", summary.GCCollections,
+			summary.GCDuration/float64(summary.GCCollections))
+	} else {
+		fmt.Fprintf(out, "GC: no collections observed\n")
+	}
+
+	alertTypes := make([]types.AlertType, 0, len(summary.Alerts))
+	total := 0
+	for alertType, severities := range summary.Alerts {
+		alertTypes = append(alertTypes, alertType)
+		for _, count := range severities {
+			total += count
+		}
+	}
+	sort.Slice(alertTypes, func(i, j int) bool { return alertTypes[i] < alertTypes[j] })
+
+	fmt.Fprintf(out, "Alerts: %d\n", total)
+	for _, alertType := range alertTypes {
+		severities := make([]types.AlertSeverity, 0, len(summary.Alerts[alertType]))
+		for severity := range summary.Alerts[alertType] {
+			severities = append(severities, severity)
+		}
+		sort.Slice(severities, func(i, j int) bool { return severities[i].Rank() > severities[j].Rank() })
+
+		counts := make([]string, len(severities))
+		for i, severity := range severities {
+			counts[i] = fmt.Sprintf("%d %s", summary.Alerts[alertType][severity], severity)
+		}
+		fmt.Fprintf(out, "  %s: %s\n", alertType, strings.Join(counts, ", "))
+	}
+}
+
+// IsTerminal reports whether f is attached to an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
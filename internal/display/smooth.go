@@ -0,0 +1,52 @@
+package display
+
+import "stackpulse/internal/types"
+
+// SmoothingRenderer averages the last few inspector-derived samples (heap
+// and GC figures) before passing a status on to the wrapped renderer. Only
+// the rendered copy is smoothed; alerts and exported statuses stay raw.
+type SmoothingRenderer struct {
+	next    Renderer
+	size    int
+	samples []smoothSample
+}
+
+type smoothSample struct {
+	heapUsed   float64
+	heapTotal  float64
+	external   float64
+	gcDuration float64
+}
+
+// NewSmoothingRenderer wraps next, averaging over the last samples statuses.
+func NewSmoothingRenderer(next Renderer, samples int) *SmoothingRenderer {
+	return &SmoothingRenderer{next: next, size: samples}
+}
+
+func (s *SmoothingRenderer) Update(status *types.Status) {
+	s.samples = append(s.samples, smoothSample{
+		heapUsed:   float64(status.Memory.HeapUsed),
+		heapTotal:  float64(status.Memory.HeapTotal),
+		external:   float64(status.Memory.External),
+		gcDuration: status.GC.Duration,
+	})
+	if len(s.samples) > s.size {
+		s.samples = s.samples[len(s.samples)-s.size:]
+	}
+
+	var avg smoothSample
+	for _, sample := range s.samples {
+		avg.heapUsed += sample.heapUsed
+		avg.heapTotal += sample.heapTotal
+		avg.external += sample.external
+		avg.gcDuration += sample.gcDuration
+	}
+	n := float64(len(s.samples))
+
+	smoothed := *status
+	smoothed.Memory.HeapUsed = uint64(avg.heapUsed / n)
+	smoothed.Memory.HeapTotal = uint64(avg.heapTotal / n)
+	smoothed.Memory.External = uint64(avg.external / n)
+	smoothed.GC.Duration = avg.gcDuration / n
+	s.next.Update(&smoothed)
+}
@@ -0,0 +1,39 @@
+package display
+
+import (
+	"math"
+	"strings"
+)
+
+// Samples drawn in the dashboard's trend column
+const sparklineWidth = 20
+
+// Sparkline draws the last sparklineWidth values as one row of blocks,
+// scaled from the smallest to the largest value shown so a trend stands out
+// however small. It is right-aligned and padded with blanks until there are
+// enough samples, and entirely blank with fewer than two.
+func Sparkline(values []float64) string {
+	if len(values) > sparklineWidth {
+		values = values[len(values)-sparklineWidth:]
+	}
+	if len(values) < 2 {
+		return strings.Repeat(" ", sparklineWidth)
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", sparklineWidth-len(values)))
+	for _, v := range values {
+		level := 0
+		if max > min {
+			level = int((v - min) / (max - min) * float64(len(chartBlocks)-1))
+		}
+		b.WriteRune(chartBlocks[level])
+	}
+	return b.String()
+}
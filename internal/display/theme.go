@@ -0,0 +1,134 @@
+package display
+
+import (
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Theme is the palette of the dashboards, one ANSI style per role, so the
+// colors that only read well on a dark background can be swapped for a
+// light one.
+type Theme struct {
+	// States of a metric, service or alert
+	OK       []int
+	Warning  []int
+	Critical []int
+
+	// Current values shaded by how close they are to their threshold: below
+	// half of it, from half, 60%, 75% and 90%, and at or past it
+	Gradient [6][]int
+
+	Header  []int // banners and the metrics table header
+	Section []int // advanced metrics and markers
+	Title   []int // monitored process and focus view titles
+	Alert   []int // active alerts heading
+}
+
+var themes = map[string]Theme{
+	"dark": {
+		OK:       []int{tablewriter.FgGreenColor},
+		Warning:  []int{tablewriter.FgYellowColor},
+		Critical: []int{tablewriter.FgRedColor},
+		Gradient: [6][]int{
+			{tablewriter.FgGreenColor},
+			{tablewriter.FgHiGreenColor},
+			{tablewriter.FgHiYellowColor},
+			{tablewriter.FgYellowColor},
+			{tablewriter.FgRedColor},
+			{tablewriter.Bold, tablewriter.FgRedColor},
+		},
+		Header:  []int{tablewriter.Bold, tablewriter.FgCyanColor},
+		Section: []int{tablewriter.Bold, tablewriter.FgMagentaColor},
+		Title:   []int{tablewriter.Bold, tablewriter.FgGreenColor},
+		Alert:   []int{tablewriter.Bold, tablewriter.FgRedColor},
+	},
+	// Yellow, cyan and the bright colors wash out on white, so warnings
+	// turn magenta and headers blue
+	"light": {
+		OK:       []int{tablewriter.FgGreenColor},
+		Warning:  []int{tablewriter.FgMagentaColor},
+		Critical: []int{tablewriter.FgRedColor},
+		Gradient: [6][]int{
+			{tablewriter.FgGreenColor},
+			{tablewriter.FgGreenColor},
+			{tablewriter.FgMagentaColor},
+			{tablewriter.Bold, tablewriter.FgMagentaColor},
+			{tablewriter.FgRedColor},
+			{tablewriter.Bold, tablewriter.FgRedColor},
+		},
+		Header:  []int{tablewriter.Bold, tablewriter.FgBlueColor},
+		Section: []int{tablewriter.Bold, tablewriter.FgBlueColor},
+		Title:   []int{tablewriter.Bold, tablewriter.FgGreenColor},
+		Alert:   []int{tablewriter.Bold, tablewriter.FgRedColor},
+	},
+}
+
+// LookupTheme returns the named theme; an empty name is the dark theme.
+func LookupTheme(name string) (Theme, bool) {
+	if name == "" {
+		name = "dark"
+	}
+	theme, ok := themes[name]
+	return theme, ok
+}
+
+// ColorMode decides whether the dashboards use color at all.
+type ColorMode int
+
+const (
+	// ColorAuto colors a terminal unless NO_COLOR is set or TERM is dumb
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+var colorModes = map[string]ColorMode{
+	"auto":   ColorAuto,
+	"always": ColorAlways,
+	"never":  ColorNever,
+}
+
+// LookupColorMode returns the named color mode; an empty name is auto.
+func LookupColorMode(name string) (ColorMode, bool) {
+	if name == "" {
+		return ColorAuto, true
+	}
+	mode, ok := colorModes[name]
+	return mode, ok
+}
+
+// SetColorMode turns color on or off for the whole process. ColorAuto
+// leaves the detection of fatih/color in place.
+func SetColorMode(mode ColorMode) {
+	switch mode {
+	case ColorAlways:
+		color.NoColor = false
+	case ColorNever:
+		color.NoColor = true
+	}
+}
+
+// cell returns style as the colors of a table cell. tablewriter does not
+// look at the color mode itself, so it gets no colors while color is off.
+func cell(style []int) tablewriter.Colors {
+	if color.NoColor {
+		return tablewriter.Colors{}
+	}
+	return tablewriter.Colors(style)
+}
+
+// text returns style for printing while color is on. color.New disables
+// itself when NO_COLOR is set, so it is told the mode to let ColorAlways win.
+func text(style []int) *color.Color {
+	attrs := make([]color.Attribute, len(style))
+	for i, code := range style {
+		attrs[i] = color.Attribute(code)
+	}
+	c := color.New(attrs...)
+	if color.NoColor {
+		c.DisableColor()
+	} else {
+		c.EnableColor()
+	}
+	return c
+}
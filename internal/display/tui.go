@@ -0,0 +1,340 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"stackpulse/internal/types"
+)
+
+// Statuses kept per process for the trend column
+const tuiHistorySize = 120
+
+// Log lines kept below the alert list
+const tuiLogLines = 3
+
+// Alert list lines shown however little room the metrics leave
+const tuiMinAlertLines = 3
+
+// TUI is a full-screen alternative to the Dashboard built on bubbletea. It
+// draws the metric tables of its Dashboard in place instead of clearing
+// and reprinting the screen, with one tab per process it receives
+// statuses for. Space pauses the view, Tab and the arrow keys switch tabs
+// and scroll the alert list, and q quits.
+//
+// Update and SetDown only queue their message for the TUI, so they may be
+// called from any goroutine once Run has been started.
+type TUI struct {
+	program *tea.Program
+}
+
+// tuiStatusMsg carries a status from Update to the model.
+type tuiStatusMsg struct{ status types.Status }
+
+// tuiDownMsg marks the tab of a process whose monitoring ended.
+type tuiDownMsg struct {
+	pid int
+	err error
+}
+
+// tuiLogMsg is one line written to the log while the TUI runs.
+type tuiLogMsg string
+
+// NewTUI creates a TUI drawing its metrics with dashboard, which it takes
+// over: the dashboard must not be used on its own afterwards.
+func NewTUI(dashboard *Dashboard) *TUI {
+	buf := &bytes.Buffer{}
+	dashboard.out = buf
+	model := &tuiModel{dashboard: dashboard, buf: buf}
+	return &TUI{program: tea.NewProgram(model, tea.WithAltScreen(), tea.WithoutSignalHandler())}
+}
+
+// Update shows status on the tab of its process, opening one for a new
+// process.
+func (t *TUI) Update(status *types.Status) {
+	t.program.Send(tuiStatusMsg{status: *status})
+}
+
+// SetDown marks the tab of pid as no longer monitored, with the error that
+// ended it or the process exit when err is nil.
+func (t *TUI) SetDown(pid int, err error) {
+	t.program.Send(tuiDownMsg{pid: pid, err: err})
+}
+
+// Write shows a log line below the alert list. Run points the standard
+// logger here so warnings don't scroll the screen.
+func (t *TUI) Write(p []byte) (int, error) {
+	t.program.Send(tuiLogMsg(strings.TrimRight(string(p), "\n")))
+	return len(p), nil
+}
+
+// Run takes over the terminal until q is pressed or ctx ends, and restores
+// it before returning.
+func (t *TUI) Run(ctx context.Context) error {
+	previous := log.Writer()
+	log.SetOutput(t)
+	defer log.SetOutput(previous)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.program.Quit()
+		case <-done:
+		}
+	}()
+
+	if _, err := t.program.Run(); err != nil {
+		return fmt.Errorf("failed to run TUI: %w", err)
+	}
+	return nil
+}
+
+// tuiTab is the view of one process.
+type tuiTab struct {
+	pid int
+
+	// Recent statuses, newest last, and while paused those shown
+	history []types.Status
+	frozen  []types.Status
+
+	// Why monitoring ended, once it has
+	down    bool
+	downErr error
+}
+
+// shown returns the statuses on screen.
+func (t *tuiTab) shown(paused bool) []types.Status {
+	if paused {
+		return t.frozen
+	}
+	return t.history
+}
+
+type tuiModel struct {
+	dashboard *Dashboard
+	buf       *bytes.Buffer
+
+	tabs   []*tuiTab
+	active int
+	paused bool
+
+	// First line of the alert list shown
+	scroll int
+
+	logs          []string
+	width, height int
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		return m, m.handleKey(msg.String())
+	case tuiStatusMsg:
+		tab := m.tab(msg.status.PID)
+		tab.history = append(tab.history, msg.status)
+		if len(tab.history) > tuiHistorySize {
+			tab.history = tab.history[len(tab.history)-tuiHistorySize:]
+		}
+		tab.down, tab.downErr = false, nil
+	case tuiDownMsg:
+		tab := m.tab(msg.pid)
+		tab.down, tab.downErr = true, msg.err
+	case tuiLogMsg:
+		m.logs = append(m.logs, string(msg))
+		if len(m.logs) > tuiLogLines {
+			m.logs = m.logs[len(m.logs)-tuiLogLines:]
+		}
+	}
+	return m, nil
+}
+
+// tab returns the tab of pid, opening it in PID order if it is new.
+func (m *tuiModel) tab(pid int) *tuiTab {
+	for _, tab := range m.tabs {
+		if tab.pid == pid {
+			return tab
+		}
+	}
+
+	var current *tuiTab
+	if len(m.tabs) > 0 {
+		current = m.tabs[m.active]
+	}
+	tab := &tuiTab{pid: pid}
+	m.tabs = append(m.tabs, tab)
+	sort.Slice(m.tabs, func(i, j int) bool { return m.tabs[i].pid < m.tabs[j].pid })
+	for i := range m.tabs {
+		if m.tabs[i] == current {
+			m.active = i
+		}
+	}
+	return tab
+}
+
+func (m *tuiModel) handleKey(key string) tea.Cmd {
+	switch key {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case " ":
+		m.paused = !m.paused
+		for _, tab := range m.tabs {
+			tab.frozen = tab.history
+		}
+	case "tab", "right", "l":
+		if len(m.tabs) > 0 {
+			m.active = (m.active + 1) % len(m.tabs)
+			m.scroll = 0
+		}
+	case "shift+tab", "left", "h":
+		if len(m.tabs) > 0 {
+			m.active = (m.active + len(m.tabs) - 1) % len(m.tabs)
+			m.scroll = 0
+		}
+	case "down", "j":
+		m.scroll++
+	case "up", "k":
+		m.scroll--
+	case "pgdown":
+		m.scroll += max(m.height/2, 1)
+	case "pgup":
+		m.scroll -= max(m.height/2, 1)
+	case "home", "g":
+		m.scroll = 0
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+	return nil
+}
+
+func (m *tuiModel) View() string {
+	var lines []string
+	lines = append(lines, m.tabBar(), "")
+
+	var shown []types.Status
+	if len(m.tabs) > 0 {
+		shown = m.tabs[m.active].shown(m.paused)
+	}
+	if len(shown) == 0 {
+		lines = append(lines, "Waiting for the first sample...")
+	} else {
+		status := &shown[len(shown)-1]
+		lines = append(lines, m.metrics(status, shown)...)
+	}
+
+	footer := append([]string(nil), m.logs...)
+	footer = append(footer, "space pause · tab/←/→ switch process · ↑/↓ pgup/pgdn scroll alerts · q quit")
+
+	alerts := m.alertLines(shown)
+	room := len(alerts)
+	if m.height > 0 {
+		// A screen too short for everything cuts the metrics, keeping a few
+		// alert lines and the footer
+		reserved := len(footer) + 1 + min(len(alerts), tuiMinAlertLines)
+		if len(lines) > m.height-reserved {
+			lines = lines[:max(m.height-reserved, 1)]
+		}
+		room = max(m.height-len(lines)-len(footer)-1, 0)
+	}
+	m.scroll = min(m.scroll, max(len(alerts)-room, 0))
+
+	heading := title(m.dashboard.glyphs.History, "Alerts")
+	if len(alerts) > room {
+		heading += fmt.Sprintf("  (lines %d-%d of %d)", m.scroll+1, min(m.scroll+room, len(alerts)), len(alerts))
+	}
+	lines = append(lines, text(m.dashboard.theme.Header).Sprint(heading))
+	lines = append(lines, alerts[m.scroll:min(m.scroll+room, len(alerts))]...)
+	lines = append(lines, footer...)
+	return strings.Join(lines, "\n")
+}
+
+// tabBar lists the processes, the active one highlighted, and whether the
+// view is paused.
+func (m *tuiModel) tabBar() string {
+	theme := m.dashboard.theme
+	parts := []string{text(theme.Header).Sprint("STACKPULSE")}
+	for i, tab := range m.tabs {
+		label := fmt.Sprintf(" PID %d ", tab.pid)
+		style := text(theme.Title)
+		switch {
+		case tab.down:
+			label = fmt.Sprintf(" PID %d (down) ", tab.pid)
+			style = text(theme.Critical)
+		case len(tab.history) > 0 && len(tab.history[len(tab.history)-1].Alerts) > 0:
+			style = text(theme.Alert)
+		}
+		if i == m.active {
+			label = "[" + strings.TrimSpace(label) + "]"
+			style = style.Add(color.Bold)
+		}
+		parts = append(parts, style.Sprint(label))
+	}
+	if m.paused {
+		parts = append(parts, text(theme.Warning).Sprint("PAUSED"))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// metrics draws the dashboard's tables for status, with history as the
+// source of the trend column.
+func (m *tuiModel) metrics(status *types.Status, history []types.Status) []string {
+	d := m.dashboard
+	d.history = func() []types.Status { return history }
+	m.buf.Reset()
+
+	tab := m.tabs[m.active]
+	if tab.down {
+		reason := fmt.Sprintf("process %d exited", tab.pid)
+		if tab.downErr != nil {
+			reason = tab.downErr.Error()
+		}
+		text(d.theme.Critical).Fprintf(m.buf, "%s\n\n", title(d.glyphs.Critical, "Not monitored: "+reason))
+	}
+	d.displayDegraded(status)
+	d.displayMetrics(status)
+	d.displayAnnotations(status)
+	return strings.Split(strings.TrimRight(m.buf.String(), "\n"), "\n")
+}
+
+// alertLines lists the active alerts of the newest status, then its recent
+// transitions, newest first.
+func (m *tuiModel) alertLines(shown []types.Status) []string {
+	if len(shown) == 0 {
+		return nil
+	}
+	status := &shown[len(shown)-1]
+	theme := m.dashboard.theme
+
+	var lines []string
+	if len(status.Alerts) == 0 {
+		lines = append(lines, text(theme.OK).Sprint(title(m.dashboard.glyphs.OK, "No active alerts")))
+	}
+	for _, alert := range status.Alerts {
+		lines = append(lines, text(theme.Alert).Sprintf("  ACTIVE    [%s] %s (%.2f > %.2f)",
+			alert.Severity, alert.Message, alert.Value, alert.Threshold))
+	}
+	for i := len(status.AlertEvents) - 1; i >= 0; i-- {
+		event := status.AlertEvents[i]
+		label, style := "FIRED   ", text(theme.Critical)
+		if event.Resolved {
+			label, style = "RESOLVED", text(theme.OK)
+		}
+		lines = append(lines, fmt.Sprintf("  %s  %s  [%s] %s",
+			event.Timestamp.Format("15:04:05"), style.Sprint(label), event.Alert.Severity, event.Alert.Message))
+	}
+	return lines
+}
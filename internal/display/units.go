@@ -0,0 +1,66 @@
+package display
+
+import "fmt"
+
+// UnitMode is how byte sizes are written on the dashboard.
+type UnitMode int
+
+const (
+	// UnitsAuto scales each size to the largest fitting binary unit
+	// (B, KiB, MiB, GiB, TiB)
+	UnitsAuto UnitMode = iota
+	// UnitsSI writes sizes in decimal megabytes (10^6 bytes), as most
+	// system monitors do
+	UnitsSI
+	// UnitsIEC writes sizes in mebibytes (2^20 bytes)
+	UnitsIEC
+)
+
+var unitModes = map[string]UnitMode{
+	"auto": UnitsAuto,
+	"si":   UnitsSI,
+	"iec":  UnitsIEC,
+}
+
+// LookupUnits returns the named unit mode; an empty name is UnitsAuto.
+func LookupUnits(name string) (UnitMode, bool) {
+	if name == "" {
+		name = "auto"
+	}
+	mode, ok := unitModes[name]
+	return mode, ok
+}
+
+// FormatBytes writes n bytes in the unit of mode, e.g. "45.2 MiB".
+func FormatBytes(n uint64, mode UnitMode) string {
+	switch mode {
+	case UnitsSI:
+		return fmt.Sprintf("%.1f MB", float64(n)/1e6)
+	case UnitsIEC:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	}
+	return scaleBytes(float64(n), 1024, []string{"B", "KiB", "MiB", "GiB", "TiB"})
+}
+
+// chartUnit is the fixed unit byte charts are drawn in, which cannot
+// rescale from one sample to the next: MB for UnitsSI, MiB otherwise.
+func chartUnit(mode UnitMode) (divisor float64, suffix string) {
+	if mode == UnitsSI {
+		return 1e6, "MB"
+	}
+	return 1 << 20, "MiB"
+}
+
+// scaleBytes writes v in the largest of units, each base times the one
+// before, that keeps the value at least 1.
+func scaleBytes(v, base float64, units []string) string {
+	i := 0
+	for v >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", v, units[0])
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
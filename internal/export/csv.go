@@ -0,0 +1,130 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+var csvHeader = []string{
+	"timestamp",
+	"cpu_usage",
+	"rss_bytes",
+	"heap_used_bytes",
+	"heap_total_bytes",
+	"event_loop_lag_ms",
+	"event_loop_p95_ms",
+	"gc_duration_ms",
+	"active_handles",
+}
+
+// CSVWriter appends the scalar metrics of each status as one CSV row,
+// flushed as it is written. The header is written whenever the file is
+// empty, so appending to an existing export keeps a single header.
+//
+// When the file is removed or renamed underneath the writer, e.g. by
+// logrotate, or a write fails, the path is reopened and the row written to
+// the new file.
+type CSVWriter struct {
+	path string
+	file *os.File
+	csv  *csv.Writer
+}
+
+func NewCSVWriter(path string) (*CSVWriter, error) {
+	w := &CSVWriter{path: path}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *CSVWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", w.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", w.path, err)
+	}
+
+	w.file = file
+	w.csv = csv.NewWriter(file)
+	if info.Size() == 0 {
+		return w.writeRow(csvHeader)
+	}
+	return nil
+}
+
+func (w *CSVWriter) reopen() error {
+	w.file.Close()
+	return w.open()
+}
+
+// moved reports whether the path no longer names the open file.
+func (w *CSVWriter) moved() bool {
+	current, err := os.Stat(w.path)
+	if err != nil {
+		return true
+	}
+	open, err := w.file.Stat()
+	return err != nil || !os.SameFile(current, open)
+}
+
+func (w *CSVWriter) writeRow(row []string) error {
+	w.csv.Write(row)
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *CSVWriter) Write(status *types.Status) error {
+	if w.moved() {
+		if err := w.reopen(); err != nil {
+			return err
+		}
+	}
+
+	row := csvRow(status)
+	if err := w.writeRow(row); err != nil {
+		// Retry once on a fresh file before giving up on this row
+		if err := w.reopen(); err != nil {
+			return err
+		}
+		if err := w.writeRow(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *CSVWriter) Close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to flush CSV output: %w", err)
+	}
+	return w.file.Close()
+}
+
+func csvRow(status *types.Status) []string {
+	float := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return []string{
+		status.Timestamp.Format(time.RFC3339Nano),
+		float(status.CPU.Percent()),
+		strconv.FormatUint(status.Memory.RSS, 10),
+		strconv.FormatUint(status.Memory.HeapUsed, 10),
+		strconv.FormatUint(status.Memory.HeapTotal, 10),
+		float(status.EventLoop.Lag),
+		float(status.EventLoop.P95),
+		float(status.GC.Duration),
+		strconv.Itoa(status.Handles.Active),
+	}
+}
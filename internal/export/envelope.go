@@ -0,0 +1,19 @@
+package export
+
+import "stackpulse/internal/types"
+
+// SchemaVersion is the version of the status JSON written by --output json
+// and served by GET /status. It is bumped when a field is renamed, removed
+// or changes meaning; new fields leave it alone.
+const SchemaVersion = 1
+
+// StatusEnvelope is a status as written for consumers: the status fields at
+// the top level, next to the schema version they follow.
+type StatusEnvelope struct {
+	SchemaVersion int `json:"schemaVersion"`
+	*types.Status
+}
+
+func NewStatusEnvelope(status *types.Status) StatusEnvelope {
+	return StatusEnvelope{SchemaVersion: SchemaVersion, Status: status}
+}
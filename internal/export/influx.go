@@ -0,0 +1,241 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Measurement of every point written in the InfluxDB line protocol
+const influxMeasurement = "stackpulse"
+
+// Points waiting for the InfluxDB worker; further points are dropped
+const influxQueueSize = 256
+
+// Escapes commas, equals signs and spaces in tag keys, tag values and field
+// keys, which would otherwise end them
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", " ")
+
+type influxField struct {
+	key   string
+	value string
+}
+
+// WriteInflux writes status as one InfluxDB line protocol point: the
+// measurement stackpulse tagged with host and pid, a field for every scalar
+// metric in the units of types.Status, and the sample time in nanoseconds.
+func WriteInflux(out io.Writer, status *types.Status, host string) error {
+	_, err := out.Write(appendInflux(nil, status, host))
+	return err
+}
+
+func appendInflux(buf []byte, status *types.Status, host string) []byte {
+	buf = append(buf, influxMeasurement...)
+	if host != "" {
+		buf = append(buf, ",host="...)
+		buf = append(buf, influxEscaper.Replace(host)...)
+	}
+	buf = append(buf, ",pid="...)
+	buf = strconv.AppendInt(buf, int64(status.PID), 10)
+
+	for i, field := range influxFields(status) {
+		if i == 0 {
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, influxEscaper.Replace(field.key)...)
+		buf = append(buf, '=')
+		buf = append(buf, field.value...)
+	}
+
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, status.Timestamp.UnixNano(), 10)
+	return append(buf, '\n')
+}
+
+// influxFields lists the scalar metrics of status. Counts and byte sizes
+// are integers; NaN and infinite values, which the protocol cannot carry,
+// are left out.
+func influxFields(status *types.Status) []influxField {
+	var fields []influxField
+	float := func(key string, v float64) {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			fields = append(fields, influxField{key, strconv.FormatFloat(v, 'g', -1, 64)})
+		}
+	}
+	integer := func(key string, v int64) {
+		fields = append(fields, influxField{key, strconv.FormatInt(v, 10) + "i"})
+	}
+
+	// The first sample of a process has no usage to report yet
+	if !status.CPU.FirstSample {
+		float("cpu_usage", status.CPU.Usage)
+	}
+	float("cpu_user_seconds", status.CPU.UserTime)
+	float("cpu_system_seconds", status.CPU.SystemTime)
+	integer("rss_bytes", int64(status.Memory.RSS))
+	integer("vms_bytes", int64(status.Memory.VMS))
+	integer("heap_used_bytes", int64(status.Memory.HeapUsed))
+	integer("heap_total_bytes", int64(status.Memory.HeapTotal))
+	integer("external_bytes", int64(status.Memory.External))
+	if status.Memory.Extended {
+		integer("swap_bytes", int64(status.Memory.Swap))
+		integer("shared_bytes", int64(status.Memory.Shared))
+	}
+	float("event_loop_lag_ms", status.EventLoop.Lag)
+	float("event_loop_mean_ms", status.EventLoop.Mean)
+	float("event_loop_max_ms", status.EventLoop.Max)
+	float("event_loop_p95_ms", status.EventLoop.P95)
+	float("event_loop_utilization", status.EventLoop.Utilization)
+	integer("threadpool_queue", int64(status.ThreadPool.QueueSize))
+	integer("threadpool_active", int64(status.ThreadPool.ActiveCount))
+	integer("gc_collections", int64(status.GC.Collections))
+	float("gc_duration_ms", status.GC.Duration)
+	integer("gc_collections_total", int64(status.GC.CollectionsTotal))
+	float("gc_duration_total_ms", status.GC.DurationTotal)
+	integer("active_handles", int64(status.Handles.Active))
+	integer("open_fds", int64(status.Handles.FDs))
+	integer("v8_malloced_bytes", int64(status.V8.MallocedMemory))
+	if status.Network != nil {
+		float("network_rx_bytes_per_second", status.Network.RxRate)
+		float("network_tx_bytes_per_second", status.Network.TxRate)
+	}
+	for _, name := range sortedKeys(status.Custom) {
+		float("custom_"+name, status.Custom[name])
+	}
+	float("uptime_seconds", status.Uptime)
+	integer("active_alerts", int64(len(status.Alerts)))
+	return fields
+}
+
+// InfluxWriter sends statuses to the write API of InfluxDB v2. Points are
+// queued for a single background worker, which sends all that are queued
+// in one request, so a slow or failing server never holds up polling. When
+// the queue is full the point is dropped and counted; points of a failed
+// request are logged and discarded.
+type InfluxWriter struct {
+	url    string
+	token  string
+	host   string
+	client *http.Client
+
+	queue   chan []byte
+	dropped atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewInfluxWriter starts the worker writing to bucket of org on the server
+// at baseURL; Close stops it. token, when set, authorizes the writes.
+func NewInfluxWriter(baseURL, org, bucket, token string) (*InfluxWriter, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid InfluxDB URL %q: %w", baseURL, err)
+	}
+	u = u.JoinPath("api", "v2", "write")
+	u.RawQuery = url.Values{"org": {org}, "bucket": {bucket}, "precision": {"ns"}}.Encode()
+
+	host, _ := os.Hostname()
+	w := &InfluxWriter{
+		url:    u.String(),
+		token:  token,
+		host:   host,
+		client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan []byte, influxQueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write queues status for the next request and returns at once.
+func (w *InfluxWriter) Write(status *types.Status) error {
+	select {
+	case w.queue <- appendInflux(nil, status, w.host):
+		return nil
+	default:
+		return fmt.Errorf("InfluxDB queue full: dropped point (%d in total)", w.dropped.Add(1))
+	}
+}
+
+// Dropped returns the number of points dropped because the queue was full.
+func (w *InfluxWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close sends the points still queued and stops the worker.
+func (w *InfluxWriter) Close() error {
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+func (w *InfluxWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case point := <-w.queue:
+			w.send(w.drain(point))
+		case <-w.stop:
+			if batch := w.drain(nil); len(batch) > 0 {
+				w.send(batch)
+			}
+			return
+		}
+	}
+}
+
+// drain appends every queued point to batch.
+func (w *InfluxWriter) drain(batch []byte) []byte {
+	for {
+		select {
+		case point := <-w.queue:
+			batch = append(batch, point...)
+		default:
+			return batch
+		}
+	}
+}
+
+func (w *InfluxWriter) send(batch []byte) {
+	if err := w.post(batch); err != nil {
+		log.Printf("Warning: Failed to write to InfluxDB: %v", err)
+	}
+}
+
+func (w *InfluxWriter) post(batch []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(batch))
+	if err != nil {
+		return fmt.Errorf("failed to create InfluxDB request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post points: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB rejected points: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"stackpulse/internal/types"
+)
+
+// JSONLWriter appends each status as one JSON document per line, the
+// capture format read back for replay.
+type JSONLWriter struct {
+	out io.WriteCloser
+}
+
+func NewJSONLWriter(out io.WriteCloser) *JSONLWriter {
+	return &JSONLWriter{out: out}
+}
+
+func (w *JSONLWriter) Write(status *types.Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	if _, err := w.out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+	return nil
+}
+
+func (w *JSONLWriter) Close() error {
+	return w.out.Close()
+}
+
+// Longest status line accepted by JSONLReader
+const maxJSONLLine = 16 * 1024 * 1024
+
+// JSONLReader reads back statuses written by JSONLWriter, one per line.
+type JSONLReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewJSONLReader(in io.Reader) *JSONLReader {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLLine)
+	return &JSONLReader{scanner: scanner}
+}
+
+// Read returns the next status, skipping blank lines, or io.EOF at the end
+// of the input.
+func (r *JSONLReader) Read() (*types.Status, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := r.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var status types.Status
+		if err := json.Unmarshal(data, &status); err != nil {
+			return nil, fmt.Errorf("failed to decode status on line %d: %w", r.line, err)
+		}
+		return &status, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read line %d: %w", r.line+1, err)
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stackpulse/internal/types"
+)
+
+// Prometheus metric types
+const (
+	gauge   = "gauge"
+	counter = "counter"
+)
+
+// family is one metric in the text exposition format. Names follow the
+// Prometheus conventions: base units (bytes, seconds, ratios) as suffixes,
+// and counters ending in _total.
+type family struct {
+	name    string
+	help    string
+	kind    string
+	samples []promSample
+}
+
+type promSample struct {
+	labels map[string]string
+	value  float64
+}
+
+func single(name, help, kind string, value float64) family {
+	return family{name: name, help: help, kind: kind, samples: []promSample{{value: value}}}
+}
+
+// WritePrometheus writes status in the Prometheus text exposition format.
+// Monotonic totals are counters so rate() works; everything else is a gauge.
+// Millisecond and percentage values are converted to seconds and ratios.
+func WritePrometheus(out io.Writer, status *types.Status) error {
+	w := bufio.NewWriter(out)
+	for _, f := range prometheusFamilies(status) {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.samples {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	return w.Flush()
+}
+
+func prometheusFamilies(status *types.Status) []family {
+	ms := func(v float64) float64 { return v / 1000 }
+	pct := func(v float64) float64 { return v / 100 }
+
+	families := []family{
+		single("stackpulse_process_start_time_seconds", "Start time of the monitored process since the Unix epoch.", gauge, float64(status.StartedAt.Unix())),
+		single("stackpulse_cpu_usage_ratio", "CPU usage of the process (1 = one core).", gauge, pct(status.CPU.Usage)),
+		single("stackpulse_cpu_user_seconds_total", "User CPU time consumed by the process.", counter, status.CPU.UserTime),
+		single("stackpulse_cpu_system_seconds_total", "System CPU time consumed by the process.", counter, status.CPU.SystemTime),
+		single("stackpulse_memory_rss_bytes", "Resident set size.", gauge, float64(status.Memory.RSS)),
+		single("stackpulse_memory_vms_bytes", "Virtual memory size.", gauge, float64(status.Memory.VMS)),
+		single("stackpulse_heap_used_bytes", "V8 heap in use.", gauge, float64(status.Memory.HeapUsed)),
+		single("stackpulse_heap_total_bytes", "V8 heap allocated.", gauge, float64(status.Memory.HeapTotal)),
+		single("stackpulse_external_memory_bytes", "Memory of C++ objects bound to JavaScript objects.", gauge, float64(status.Memory.External)),
+		single("stackpulse_eventloop_lag_seconds", "Latest event loop lag.", gauge, ms(status.EventLoop.Lag)),
+		{
+			name: "stackpulse_eventloop_lag_window_seconds",
+			help: "Event loop lag over recent samples, by statistic.",
+			kind: gauge,
+			samples: []promSample{
+				{labels: map[string]string{"stat": "min"}, value: ms(status.EventLoop.Min)},
+				{labels: map[string]string{"stat": "mean"}, value: ms(status.EventLoop.Mean)},
+				{labels: map[string]string{"stat": "p95"}, value: ms(status.EventLoop.P95)},
+				{labels: map[string]string{"stat": "max"}, value: ms(status.EventLoop.Max)},
+			},
+		},
+		single("stackpulse_eventloop_utilization_ratio", "Fraction of time the event loop was busy.", gauge, pct(status.EventLoop.Utilization)),
+		single("stackpulse_threadpool_queue_size", "Requests queued for the libuv thread pool.", gauge, float64(status.ThreadPool.QueueSize)),
+		single("stackpulse_threadpool_active_threads", "Busy libuv thread pool threads.", gauge, float64(status.ThreadPool.ActiveCount)),
+		single("stackpulse_gc_collections_total", "Garbage collections since the process started.", counter, float64(status.GC.CollectionsTotal)),
+		single("stackpulse_gc_duration_seconds_total", "Time spent in garbage collection since the process started.", counter, ms(status.GC.DurationTotal)),
+		single("stackpulse_gc_reclaim_ratio", "Fraction of the heap freed by the latest collections.", gauge, status.GC.ReclaimEfficiency),
+		single("stackpulse_handles_active", "Active libuv handles.", gauge, float64(status.Handles.Active)),
+		single("stackpulse_open_fds", "Open file descriptors.", gauge, float64(status.Handles.FDs)),
+		single("stackpulse_max_fds", "Soft limit on open file descriptors (RLIMIT_NOFILE).", gauge, float64(status.Handles.FDLimit)),
+		single("stackpulse_v8_malloced_bytes", "Memory allocated by V8 through malloc.", gauge, float64(status.V8.MallocedMemory)),
+		single("stackpulse_v8_code_bytes", "Size of compiled code.", gauge, float64(status.V8.CodeSize)),
+		heapSpaceFamily("stackpulse_v8_heap_space_used_bytes", "Used size of each V8 heap space.", status.V8.HeapSpaceUsed),
+		heapSpaceFamily("stackpulse_v8_heap_space_size_bytes", "Allocated size of each V8 heap space.", status.V8.HeapSpaceSize),
+	}
+
+	if status.Network != nil {
+		families = append(families,
+			single("stackpulse_network_receive_bytes_total", "Bytes received.", counter, float64(status.Network.BytesRecv)),
+			single("stackpulse_network_transmit_bytes_total", "Bytes sent.", counter, float64(status.Network.BytesSent)),
+		)
+	}
+
+	if len(status.Custom) > 0 {
+		custom := family{name: "stackpulse_custom_metric", help: "User-defined custom metrics.", kind: gauge}
+		for _, name := range sortedKeys(status.Custom) {
+			custom.samples = append(custom.samples, promSample{labels: map[string]string{"name": name}, value: status.Custom[name]})
+		}
+		families = append(families, custom)
+	}
+
+	alerts := family{name: "stackpulse_active_alerts", help: "Active alerts by severity.", kind: gauge}
+	for _, severity := range []types.AlertSeverity{types.SeverityWarning, types.SeverityCritical, types.SeverityEmergency} {
+		count := 0
+		for _, alert := range status.Alerts {
+			if alert.Severity == severity {
+				count++
+			}
+		}
+		alerts.samples = append(alerts.samples, promSample{labels: map[string]string{"severity": string(severity)}, value: float64(count)})
+	}
+	return append(families, alerts)
+}
+
+func heapSpaceFamily(name, help string, spaces map[string]uint64) family {
+	f := family{name: name, help: help, kind: gauge}
+	names := make([]string, 0, len(spaces))
+	for space := range spaces {
+		names = append(names, space)
+	}
+	sort.Strings(names)
+	for _, space := range names {
+		f.samples = append(f.samples, promSample{labels: map[string]string{"space": space}, value: float64(spaces[space])})
+	}
+	return f
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[name])
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// WritePrometheusFile replaces path with status in the text exposition
+// format, for the node_exporter textfile collector. The file is written
+// next to path and renamed over it so readers never see a partial file.
+func WritePrometheusFile(path string, status *types.Status) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WritePrometheus(tmp, status); err != nil {
+		tmp.Close()
+		return err
+	}
+	// CreateTemp makes the file private; the collector usually runs as
+	// another user
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set metrics file mode: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace metrics file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RotatingFile is an append-only file that is rotated once it would grow
+// past maxSize. Rotated files are renamed path.1, path.2, ... (newest first,
+// with a .gz suffix when compressed) and only the newest keep are retained.
+// A maxSize of 0 disables rotation.
+type RotatingFile struct {
+	path     string
+	maxSize  int64
+	keep     int
+	compress bool
+
+	file *os.File
+	size int64
+}
+
+func NewRotatingFile(path string, maxSize int64, keep int, compress bool) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, keep: keep, compress: compress}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", r.path, err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when p would take the file past maxSize.
+// A single write is never split across files.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	return r.file.Close()
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", r.path, err)
+	}
+
+	// Shift older generations up by one, dropping the oldest
+	os.Remove(r.rotatedName(r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		if err := os.Rename(r.rotatedName(i), r.rotatedName(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate %s: %w", r.rotatedName(i), err)
+		}
+	}
+
+	if r.keep > 0 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", r.path, err)
+		}
+		if r.compress {
+			if err := gzipFile(r.path + ".1"); err != nil {
+				return err
+			}
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", r.path, err)
+	}
+
+	return r.open()
+}
+
+func (r *RotatingFile) rotatedName(generation int) string {
+	name := fmt.Sprintf("%s.%d", r.path, generation)
+	if r.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// gzipFile replaces path with path.gz.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for compression: %w", path, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return fmt.Errorf("failed to create %s.gz: %w", path, err)
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s.gz: %w", path, err)
+	}
+	return os.Remove(path)
+}
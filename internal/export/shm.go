@@ -0,0 +1,153 @@
+//go:build unix
+
+package export
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"stackpulse/internal/types"
+)
+
+// Shared memory layout (little endian):
+//
+//	offset 0   magic    [8]byte "SPULSE01"
+//	offset 8   sequence uint64  odd while a write is in progress
+//	offset 16  length   uint32  size of the JSON document
+//	offset 20  reserved
+//	offset 32  JSON-encoded types.Status
+//
+// Readers load the sequence, copy the document, and load the sequence again;
+// the copy is consistent only if both loads return the same even value.
+const (
+	shmMagic      = "SPULSE01"
+	shmHeaderSize = 32
+
+	DefaultSharedMemorySize = 64 * 1024
+)
+
+// SharedMemoryWriter publishes the latest status into a memory-mapped file
+// so local processes can read it without a socket round trip.
+type SharedMemoryWriter struct {
+	file *os.File
+	mem  []byte
+	seq  uint64
+}
+
+func NewSharedMemoryWriter(path string, size int) (*SharedMemoryWriter, error) {
+	if size <= shmHeaderSize {
+		return nil, fmt.Errorf("shared memory size must be larger than %d bytes", shmHeaderSize)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shared memory file: %w", err)
+	}
+	if err := file.Truncate(int64(size)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to size shared memory file: %w", err)
+	}
+
+	mem, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to map shared memory file: %w", err)
+	}
+
+	copy(mem[0:8], shmMagic)
+	w := &SharedMemoryWriter{file: file, mem: mem}
+	w.storeSeq(0)
+	binary.LittleEndian.PutUint32(mem[16:20], 0)
+	return w, nil
+}
+
+func (w *SharedMemoryWriter) Write(status *types.Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	if len(data) > len(w.mem)-shmHeaderSize {
+		return fmt.Errorf("status (%d bytes) exceeds shared memory capacity (%d bytes)", len(data), len(w.mem)-shmHeaderSize)
+	}
+
+	w.seq++
+	w.storeSeq(w.seq)
+	binary.LittleEndian.PutUint32(w.mem[16:20], uint32(len(data)))
+	copy(w.mem[shmHeaderSize:], data)
+	w.seq++
+	w.storeSeq(w.seq)
+	return nil
+}
+
+func (w *SharedMemoryWriter) Close() error {
+	if err := syscall.Munmap(w.mem); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *SharedMemoryWriter) storeSeq(seq uint64) {
+	atomic.StoreUint64((*uint64)(unsafe.Pointer(&w.mem[8])), seq)
+}
+
+// ReadSharedStatus reads a consistent status document from a file written by
+// SharedMemoryWriter, retrying while a write is in progress.
+func ReadSharedStatus(path string) (*types.Status, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shared memory file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= shmHeaderSize {
+		return nil, fmt.Errorf("shared memory file is too small")
+	}
+
+	mem, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map shared memory file: %w", err)
+	}
+	defer syscall.Munmap(mem)
+
+	if string(mem[0:8]) != shmMagic {
+		return nil, fmt.Errorf("not a stackpulse shared memory file")
+	}
+
+	seqPtr := (*uint64)(unsafe.Pointer(&mem[8]))
+	for attempt := 0; attempt < 100; attempt++ {
+		before := atomic.LoadUint64(seqPtr)
+		if before%2 == 1 {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		length := int(binary.LittleEndian.Uint32(mem[16:20]))
+		if length == 0 || length > len(mem)-shmHeaderSize {
+			return nil, fmt.Errorf("no status published yet")
+		}
+		data := make([]byte, length)
+		copy(data, mem[shmHeaderSize:shmHeaderSize+length])
+
+		if atomic.LoadUint64(seqPtr) != before {
+			continue
+		}
+
+		var status types.Status
+		if err := json.Unmarshal(data, &status); err != nil {
+			return nil, fmt.Errorf("failed to decode status: %w", err)
+		}
+		return &status, nil
+	}
+	return nil, fmt.Errorf("timed out waiting for a consistent read")
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package export
+
+import (
+	"fmt"
+
+	"stackpulse/internal/types"
+)
+
+const DefaultSharedMemorySize = 64 * 1024
+
+// SharedMemoryWriter is only available on Unix-like systems.
+type SharedMemoryWriter struct{}
+
+func NewSharedMemoryWriter(path string, size int) (*SharedMemoryWriter, error) {
+	return nil, fmt.Errorf("shared memory output is not supported on this platform")
+}
+
+func (w *SharedMemoryWriter) Write(status *types.Status) error {
+	return fmt.Errorf("shared memory output is not supported on this platform")
+}
+
+func (w *SharedMemoryWriter) Close() error {
+	return nil
+}
+
+func ReadSharedStatus(path string) (*types.Status, error) {
+	return nil, fmt.Errorf("shared memory output is not supported on this platform")
+}
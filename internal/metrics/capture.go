@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Captures run on their own inspector session so they neither block nor
+// disturb the polling session (e.g. the deopt profiler started there).
+
+// CaptureCPUProfile records a CPU profile for duration and writes it to path
+// in the .cpuprofile format understood by Chrome DevTools.
+func (c *Collector) CaptureCPUProfile(ctx context.Context, inspectPort int, duration time.Duration, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, duration+c.inspectTimeout())
+	defer cancel()
+
+	client, err := c.captureSession(ctx, inspectPort)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Call(ctx, "Profiler.enable", nil, nil); err != nil {
+		return err
+	}
+	if err := client.Call(ctx, "Profiler.start", nil, nil); err != nil {
+		return fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var result struct {
+		Profile json.RawMessage `json:"profile"`
+	}
+	if err := client.Call(ctx, "Profiler.stop", nil, &result); err != nil {
+		return fmt.Errorf("failed to stop CPU profile: %w", err)
+	}
+
+	if err := os.WriteFile(path, result.Profile, 0644); err != nil {
+		return fmt.Errorf("failed to write CPU profile: %w", err)
+	}
+	return nil
+}
+
+// CaptureHeapSnapshot streams a full heap snapshot to path in the
+// .heapsnapshot format.
+func (c *Collector) CaptureHeapSnapshot(ctx context.Context, inspectPort int, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, heapSnapshotTimeout)
+	defer cancel()
+
+	client, err := c.captureSession(ctx, inspectPort)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create heap snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	var writeErr error
+	off := client.On("HeapProfiler.addHeapSnapshotChunk", func(params json.RawMessage) {
+		var chunk struct {
+			Chunk string `json:"chunk"`
+		}
+		if writeErr == nil && json.Unmarshal(params, &chunk) == nil {
+			_, writeErr = file.WriteString(chunk.Chunk)
+		}
+	})
+	defer off()
+
+	if err := client.Call(ctx, "HeapProfiler.enable", nil, nil); err != nil {
+		return err
+	}
+	params := map[string]interface{}{"reportProgress": false}
+	if err := client.Call(ctx, "HeapProfiler.takeHeapSnapshot", params, nil); err != nil {
+		return fmt.Errorf("failed to take heap snapshot: %w", err)
+	}
+
+	// Chunks are delivered on the read loop before the call's response
+	off()
+	if writeErr != nil {
+		return fmt.Errorf("failed to write heap snapshot: %w", writeErr)
+	}
+	return file.Close()
+}
+
+func (c *Collector) captureSession(ctx context.Context, inspectPort int) (*cdpClient, error) {
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+	return dialCDP(ctx, wsURL)
+}
+
+// NodeVersion returns process.version of the target.
+func (c *Collector) NodeVersion(ctx context.Context, inspectPort int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.inspectTimeout())
+	defer cancel()
+
+	client, err := c.captureSession(ctx, inspectPort)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	raw, err := client.Evaluate(ctx, "process.version")
+	if err != nil {
+		return "", err
+	}
+	var version string
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return "", fmt.Errorf("failed to parse Node version: %w", err)
+	}
+	return version, nil
+}
@@ -1,15 +1,24 @@
 package metrics
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"math"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	gnet "github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
 	"stackpulse/internal/config"
 	"stackpulse/internal/types"
@@ -20,117 +29,483 @@ type Collector struct {
 	eventLoopTimer *time.Timer
 	lastEventLoop  time.Time
 	eventLoopHist  []float64
+	lagWindows     []lagWindow
+
+	// Consecutive lag measurements that timed out, and when the first of
+	// them was sent
+	lagTimeouts  int
+	blockedSince time.Time
+
+	cdp             *cdpClient
+	profilerRunning bool
+
+	// Set while runtime statistics (--compare-runtime) keep failing, so the
+	// failure is logged once rather than every poll
+	runtimeStatsFailing bool
+
+	// Last failure to reach the inspector, returned to further discoveries
+	// for one inspect timeout so an unreachable host is not retried by every
+	// metric group of a poll
+	discoveryErr      error
+	discoveryFailedAt time.Time
+
+	lastConstructorSample time.Time
+	constructorCache      []types.ConstructorMetrics
+	constructorBaseline   map[string]uint64
+
+	lastNetwork *types.NetworkMetrics
+
+	// Handle on the monitored process, kept across polls, and the one the
+	// previous CPU times (user + system seconds) were read from and when,
+	// which the next poll's usage is measured against
+	proc      *process.Process
+	cpuProc   *process.Process
+	cpuTimes  float64
+	cpuReadAt time.Time
+
+	// Moving average of CPU usage (CPUSmoothing), started afresh from the
+	// first measured sample of each process
+	cpuSmoothed    float64
+	cpuSmoothedSet bool
+
+	// GC trace session (--gc-source trace) and the totals of either GC source
+	// since monitoring started
+	gcTrace            *gcTracer
+	gcCollectionsTotal int
+	gcDurationTotal    float64
+
+	// Which groups were read from the inspector on their latest collection
+	sources types.SourceAvailability
 }
 
 func NewCollector(cfg *config.ServiceConfig) *Collector {
 	return &Collector{
 		config:        cfg,
-		eventLoopHist: make([]float64, 0, 100), // Keep last 100 measurements
+		eventLoopHist: make([]float64, 0, historySize(cfg)),
+		lagWindows:    make([]lagWindow, 0, historySize(cfg)),
+	}
+}
+
+// Sources reports which metric groups were read from the inspector on
+// their latest collection rather than filled with fallback values.
+func (c *Collector) Sources() types.SourceAvailability {
+	return c.sources
+}
+
+// historySize is the event loop window of cfg, defaulting for configs that
+// were never validated.
+func historySize(cfg *config.ServiceConfig) int {
+	if cfg.HistorySize < 1 {
+		return config.DefaultHistorySize
 	}
+	return cfg.HistorySize
 }
 
+// FindProcessByPort returns the process with a listening socket on port,
+// found from the system's TCP socket table without connecting to it. When
+// several listen (e.g. one per address family), the one bound to the
+// configured host is preferred, then one of the host's address family.
+//
+// gopsutil reads the table from /proc on Linux, GetExtendedTcpTable on
+// Windows and lsof on macOS and the BSDs. Sockets of other users' processes
+// appear without an owner on Linux and not at all through lsof, and on
+// Windows the owner of a service may not be open to inspection. An owner
+// missing from the table or closed to inspection returns an error asking
+// for elevated privileges (see elevationHint); a listener lsof does not
+// show at all cannot be told apart from none.
 func (c *Collector) FindProcessByPort(port int) (int, error) {
-	// Find process listening on specified port
-	conn, err := net.Dial("tcp", fmt.Sprintf(":%d", port))
+	var bindIP net.IP
+	if c.config.BindAddr != "" {
+		bindIP = net.ParseIP(c.config.BindAddr)
+	}
+	hostIP := net.ParseIP(c.config.Host)
+
+	connections, err := gnet.Connections("tcp")
 	if err != nil {
-		return 0, fmt.Errorf("no process listening on port %d: %w", port, err)
+		return 0, fmt.Errorf("failed to list listening sockets: %w", err)
+	}
+
+	pid, best, hidden := 0, -1, false
+	for _, conn := range connections {
+		if int(conn.Laddr.Port) != port || conn.Status != "LISTEN" {
+			continue
+		}
+		ip := net.ParseIP(conn.Laddr.IP)
+		if bindIP != nil && !bindIP.Equal(ip) {
+			continue
+		}
+		if conn.Pid <= 0 {
+			hidden = true
+			continue
+		}
+		if score := listenerScore(ip, hostIP); score > best {
+			pid, best = int(conn.Pid), score
+		}
+	}
+	if best >= 0 {
+		if proc, err := process.NewProcess(int32(pid)); err == nil {
+			if _, err := proc.Times(); errors.Is(err, os.ErrPermission) {
+				return 0, fmt.Errorf("process %d listening on port %d cannot be inspected by this user; %s", pid, port, elevationHint)
+			}
+		}
+		return pid, nil
+	}
+
+	if hidden {
+		return 0, fmt.Errorf("port %d is in use by a process this user cannot see; %s", port, elevationHint)
+	}
+	if bindIP != nil {
+		return 0, fmt.Errorf("no process listening on port %d on %s", port, bindIP)
+	}
+	return 0, fmt.Errorf("no process listening on port %d", port)
+}
+
+// FindProcessByName returns the processes whose name or command line
+// matches the regular expression pattern, or an error when none does. This
+// process and its ancestors are left out, since their command lines usually
+// contain the pattern.
+func (c *Collector) FindProcessByName(pattern string) ([]int, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid process name pattern: %w", err)
 	}
-	conn.Close()
 
-	// This is a simplified approach - in production, you'd need to parse netstat or /proc/net/tcp
 	processes, err := process.Processes()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get processes: %w", err)
+		return nil, fmt.Errorf("failed to get processes: %w", err)
 	}
 
-	for _, p := range processes {
-		connections, err := p.Connections()
+	own := make(map[int32]bool)
+	for pid := int32(os.Getpid()); pid > 0 && !own[pid]; {
+		own[pid] = true
+		proc, err := process.NewProcess(pid)
 		if err != nil {
+			break
+		}
+		if pid, err = proc.Ppid(); err != nil {
+			break
+		}
+	}
+
+	var pids []int
+	for _, p := range processes {
+		if own[p.Pid] {
 			continue
 		}
-		
-		for _, conn := range connections {
-			if int(conn.Laddr.Port) == port {
-				return int(p.Pid), nil
+		name, _ := p.Name()
+		cmdline, _ := p.Cmdline()
+		if (name != "" && re.MatchString(name)) || (cmdline != "" && re.MatchString(cmdline)) {
+			pids = append(pids, int(p.Pid))
+		}
+	}
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process matches %q", pattern)
+	}
+	return pids, nil
+}
+
+// Port node's inspector listens on when --inspect names none
+const defaultInspectPort = 9229
+
+// InspectorPort returns the inspector port the Node.js process pid was
+// started with, from the --inspect flags on its command line. ok is false
+// when it was started without one; an inspector opened later (SIGUSR1 or
+// inspector.open()) is not found.
+func (c *Collector) InspectorPort(pid int) (port int, ok bool) {
+	proc, err := c.process(pid)
+	if err != nil {
+		return 0, false
+	}
+	args, err := proc.CmdlineSlice()
+	if err != nil {
+		return 0, false
+	}
+	return inspectorPortFromArgs(args)
+}
+
+// inspectorPortFromArgs finds the inspector port in node's arguments:
+// --inspect, --inspect-brk or --inspect-wait enable it, each optionally
+// with =[host:]port, and --inspect-port [host:]port sets the port. As in
+// node, the last port given wins. Arguments after "--" belong to the
+// script.
+func inspectorPortFromArgs(args []string) (int, bool) {
+	enabled := false
+	port := defaultInspectPort
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			break
+		}
+		flag, value, hasValue := strings.Cut(args[i], "=")
+		switch flag {
+		case "--inspect", "--inspect-brk", "--inspect-wait":
+			enabled = true
+		case "--inspect-port", "--debug-port":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value, hasValue = args[i], true
 			}
+		default:
+			continue
+		}
+		if !hasValue {
+			continue
+		}
+		// A value without a colon is a port when numeric, else a host
+		if i := strings.LastIndex(value, ":"); i >= 0 {
+			value = value[i+1:]
+		}
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 65535 {
+			port = n
 		}
 	}
+	return port, enabled
+}
 
-	return 0, fmt.Errorf("could not find process for port %d", port)
+// listenerScore ranks a listening address against the configured host: 2
+// for the host itself, 1 for the host's address family, 0 otherwise.
+func listenerScore(ip, host net.IP) int {
+	switch {
+	case ip == nil || host == nil:
+		return 0
+	case ip.Equal(host):
+		return 2
+	case (ip.To4() != nil) == (host.To4() != nil):
+		return 1
+	}
+	return 0
 }
 
-func (c *Collector) CollectCPU(pid int) (*types.CPUMetrics, error) {
+// ProcessRunning reports whether pid is a live process. Zombies, which have
+// exited but not been reaped, count as gone.
+func (c *Collector) ProcessRunning(pid int) bool {
 	proc, err := process.NewProcess(int32(pid))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get process %d: %w", pid, err)
+		return false
+	}
+	statuses, err := proc.Status()
+	if err != nil {
+		return false
+	}
+	for _, status := range statuses {
+		if status == process.Zombie {
+			return false
+		}
 	}
+	return true
+}
 
-	cpuPercent, err := proc.CPUPercent()
+// OwnsPort reports whether pid has a socket bound to the local port.
+func (c *Collector) OwnsPort(pid, port int) (bool, error) {
+	proc, err := process.NewProcess(int32(pid))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get CPU percent: %w", err)
+		return false, fmt.Errorf("failed to get process %d: %w", pid, err)
+	}
+
+	connections, err := proc.Connections()
+	if err != nil {
+		return false, fmt.Errorf("failed to list connections of process %d: %w", pid, err)
+	}
+	for _, conn := range connections {
+		if int(conn.Laddr.Port) == port {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CollectCPU measures CPU usage since the previous call, from the CPU time
+// the process consumed over the wall time between the two reads, so it
+// covers exactly one polling window whatever the interval, jitter or
+// alignment. After attaching to a new process (a different PID, or a reused
+// PID with another start time) there is nothing to measure against yet, so
+// that first sample is flagged and reports no usage instead of a figure
+// mixed from two processes.
+func (c *Collector) CollectCPU(pid int) (*types.CPUMetrics, error) {
+	proc, err := c.process(pid)
+	if err != nil {
+		return nil, err
 	}
 
 	times, err := proc.Times()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get CPU times: %w", err)
 	}
+	now := time.Now()
+	busy := times.User + times.System
+
+	firstSample := c.cpuProc != proc
+	var cpuPercent float64
+	if firstSample {
+		c.cpuSmoothed, c.cpuSmoothedSet = 0, false
+	} else {
+		cpuPercent = cpuUsage(busy-c.cpuTimes, now.Sub(c.cpuReadAt))
+	}
+	c.cpuProc, c.cpuTimes, c.cpuReadAt = proc, busy, now
+
+	if alpha := c.config.CPUSmoothing; alpha > 0 && !firstSample {
+		if c.cpuSmoothedSet {
+			c.cpuSmoothed = alpha*cpuPercent + (1-alpha)*c.cpuSmoothed
+		} else {
+			c.cpuSmoothed, c.cpuSmoothedSet = cpuPercent, true
+		}
+	}
 
+	cores := runtime.NumCPU()
 	return &types.CPUMetrics{
-		Usage:      cpuPercent,
-		UserTime:   times.User,
-		SystemTime: times.System,
-		Timestamp:  time.Now(),
+		Usage:           cpuPercent,
+		NormalizedUsage: cpuPercent / float64(cores),
+		SmoothedUsage:   c.cpuSmoothed,
+		Cores:           cores,
+		Normalized:      c.config.CPUNormalize == config.CPUNormalizeMachine,
+		Smoothed:        c.config.CPUSmoothed(),
+		UserTime:        times.User,
+		SystemTime:      times.System,
+		FirstSample:     firstSample,
+		Timestamp:       now,
 	}, nil
 }
 
-func (c *Collector) CollectMemory(pid int) (*types.MemoryMetrics, error) {
+// process returns the handle on pid kept from earlier polls, or a new one
+// when the PID changed or the handle's process is gone. gopsutil caches the
+// start time in the handle, so IsRunning tells a reused PID apart.
+func (c *Collector) process(pid int) (*process.Process, error) {
+	if c.proc != nil && int(c.proc.Pid) == pid {
+		if running, err := c.proc.IsRunning(); err == nil && running {
+			return c.proc, nil
+		}
+	}
+
 	proc, err := process.NewProcess(int32(pid))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get process %d: %w", pid, err)
 	}
+	if _, err := proc.CreateTime(); err != nil {
+		return nil, fmt.Errorf("failed to get process start time: %w", err)
+	}
+	c.proc = proc
+	return proc, nil
+}
+
+// cpuUsage is the CPU usage in percent of one core of a process that
+// consumed busy seconds of CPU time over elapsed. A negative busy time, from
+// counters that went backwards, counts as idle.
+func cpuUsage(busy float64, elapsed time.Duration) float64 {
+	if busy <= 0 || elapsed <= 0 {
+		return 0
+	}
+	return busy / elapsed.Seconds() * 100
+}
+
+// ProcessStartTime returns when the process was created. A different start
+// time for the same target means it was restarted.
+func (c *Collector) ProcessStartTime(pid int) (time.Time, error) {
+	proc, err := c.process(pid)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	createTime, err := proc.CreateTime()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get process start time: %w", err)
+	}
+	return time.UnixMilli(createTime), nil
+}
+
+func (c *Collector) CollectMemory(pid int) (*types.MemoryMetrics, error) {
+	proc, err := c.process(pid)
+	if err != nil {
+		return nil, err
+	}
 
 	memInfo, err := proc.MemoryInfo()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memory info: %w", err)
 	}
 
-	// Try to get Node.js specific memory info via V8 inspector
-	nodeMemory, err := c.getNodeMemoryInfo(pid)
-	if err != nil {
-		// Fall back to system memory info
-		return &types.MemoryMetrics{
-			RSS:       memInfo.RSS,
-			VMS:       memInfo.VMS,
-			HeapTotal: 0,
-			HeapUsed:  0,
-			External:  0,
-			Timestamp: time.Now(),
-		}, nil
+	metrics := &types.MemoryMetrics{
+		RSS: memInfo.RSS,
+		VMS: memInfo.VMS,
 	}
 
-	return &types.MemoryMetrics{
-		RSS:       memInfo.RSS,
-		VMS:       memInfo.VMS,
-		HeapTotal: nodeMemory.HeapTotal,
-		HeapUsed:  nodeMemory.HeapUsed,
-		External:  nodeMemory.External,
-		Timestamp: time.Now(),
-	}, nil
+	// Swap and shared memory are only reported by Linux; elsewhere they
+	// stay zero and unflagged
+	if runtime.GOOS == "linux" {
+		if swap, shared, err := readProcMemory(pid); err == nil {
+			metrics.Swap, metrics.Shared, metrics.Extended = swap, shared, true
+		}
+	}
+
+	// Try to get Node.js specific memory info via V8 inspector, falling
+	// back to the system memory info alone
+	nodeMemory, err := c.getHeapUsageFromInspector(c.config.InspectPort)
+	c.sources.HeapUsage = err == nil
+	if err == nil {
+		metrics.HeapTotal = nodeMemory.HeapTotal
+		metrics.HeapUsed = nodeMemory.HeapUsed
+		metrics.External = nodeMemory.External
+	}
+	metrics.Timestamp = time.Now()
+	return metrics, nil
 }
 
 func (c *Collector) CollectEventLoop(pid int, inspectPort int) (*types.EventLoopMetrics, error) {
-	// Measure event loop lag using setTimeout drift
-	lag, err := c.measureEventLoopLag(inspectPort)
-	if err != nil {
-		// Fallback to basic measurement
-		lag = 0
+	// Prefer the delay histogram the probe accumulated since the last poll,
+	// with the statistics over the windows of the last HistorySize polls
+	window, err := c.readLagProbe(inspectPort)
+	c.sources.EventLoop = err == nil
+	if err == nil && window != nil {
+		c.lagTimeouts = 0
+		if size := historySize(c.config); len(c.lagWindows) >= size {
+			drop := len(c.lagWindows) - size + 1
+			c.lagWindows = append(c.lagWindows[:0], c.lagWindows[drop:]...)
+		}
+		c.lagWindows = append(c.lagWindows, *window)
+		stats := summarizeLagWindows(c.lagWindows)
+		return &types.EventLoopMetrics{
+			Lag:         window.Mean,
+			Mean:        stats.Mean,
+			Max:         stats.Max,
+			Min:         stats.Min,
+			P95:         stats.P95,
+			Utilization: c.calculateEventLoopUtilization(window.Mean),
+			Timestamp:   time.Now(),
+		}, nil
+	}
+
+	// Measure event loop lag using setTimeout drift, unless the probe
+	// already waited out the timeout on a blocked loop
+	var lag float64
+	if !errors.Is(err, context.DeadlineExceeded) {
+		lag, err = c.measureEventLoopLag(inspectPort)
+	}
+	c.sources.EventLoop = c.sources.EventLoop || err == nil
+
+	// A loop busy with synchronous work never runs the measurement, so a
+	// timeout means it has been blocked at least since it was sent
+	var blocked float64
+	if errors.Is(err, context.DeadlineExceeded) {
+		if c.lagTimeouts == 0 {
+			c.blockedSince = time.Now().Add(-c.inspectTimeout())
+		}
+		c.lagTimeouts++
+		blocked = float64(time.Since(c.blockedSince)) / float64(time.Millisecond)
+		lag = blocked
+	} else {
+		c.lagTimeouts = 0
+		if err != nil {
+			// Fallback to basic measurement
+			lag = 0
+		}
 	}
 
 	// Add to history for statistics
-	c.eventLoopHist = append(c.eventLoopHist, lag)
-	if len(c.eventLoopHist) > 100 {
-		c.eventLoopHist = c.eventLoopHist[1:]
+	// Shift in place rather than reslicing, so the window stays within the
+	// capacity allocated by NewCollector
+	if size := historySize(c.config); len(c.eventLoopHist) >= size {
+		drop := len(c.eventLoopHist) - size + 1
+		c.eventLoopHist = append(c.eventLoopHist[:0], c.eventLoopHist[drop:]...)
 	}
+	c.eventLoopHist = append(c.eventLoopHist, lag)
 
 	// Calculate statistics
 	mean, max, min, p95 := c.calculateEventLoopStats()
@@ -144,20 +519,21 @@ func (c *Collector) CollectEventLoop(pid int, inspectPort int) (*types.EventLoop
 		P95:         p95,
 		Utilization: utilization,
 		Timestamp:   time.Now(),
+		Timeouts:    c.lagTimeouts,
+		BlockedMs:   blocked,
 	}, nil
 }
 
 func (c *Collector) CollectThreadPool(pid int) (*types.ThreadPoolMetrics, error) {
 	// Get thread pool metrics via V8 inspector
 	metrics, err := c.getThreadPoolMetrics(c.config.InspectPort)
+	c.sources.ThreadPool = err == nil
 	if err != nil {
-		// Return default values if inspector unavailable
+		// Without an inspector only the pool size is known
 		return &types.ThreadPoolMetrics{
-			QueueSize:    0,
-			PoolSize:     4, // Default libuv thread pool size
-			ActiveCount:  0,
-			PendingCount: 0,
-			Timestamp:    time.Now(),
+			PoolSize:  threadPoolSizeFromEnv(pid),
+			Estimated: true,
+			Timestamp: time.Now(),
 		}, nil
 	}
 	return metrics, nil
@@ -165,7 +541,14 @@ func (c *Collector) CollectThreadPool(pid int) (*types.ThreadPoolMetrics, error)
 
 func (c *Collector) CollectGC(pid int, inspectPort int) (*types.GCMetrics, error) {
 	// Get GC metrics via V8 inspector
-	metrics, err := c.getGCMetrics(inspectPort)
+	var metrics *types.GCMetrics
+	var err error
+	if c.config.GCSource == config.GCSourceTrace {
+		metrics, err = c.collectGCTrace(inspectPort)
+	} else {
+		metrics, err = c.getGCMetrics(inspectPort)
+	}
+	c.sources.GC = err == nil
 	if err != nil {
 		return &types.GCMetrics{
 			Collections:      0,
@@ -179,14 +562,20 @@ func (c *Collector) CollectGC(pid int, inspectPort int) (*types.GCMetrics, error
 			Timestamp:        time.Now(),
 		}, nil
 	}
+
+	// Fraction of the pre-collection heap that the last GC freed
+	if metrics.HeapSizeBefore > 0 && metrics.HeapSizeAfter <= metrics.HeapSizeBefore {
+		metrics.ReclaimEfficiency = float64(metrics.HeapSizeBefore-metrics.HeapSizeAfter) / float64(metrics.HeapSizeBefore)
+	}
 	return metrics, nil
 }
 
 func (c *Collector) CollectHandles(pid int, inspectPort int) (*types.HandleMetrics, error) {
 	// Get handle metrics via V8 inspector
 	metrics, err := c.getHandleMetrics(inspectPort)
+	c.sources.Handles = err == nil
 	if err != nil {
-		return &types.HandleMetrics{
+		metrics = &types.HandleMetrics{
 			Active:     0,
 			Refs:       0,
 			Timers:     0,
@@ -194,11 +583,32 @@ func (c *Collector) CollectHandles(pid int, inspectPort int) (*types.HandleMetri
 			UDPSockets: 0,
 			Files:      0,
 			Timestamp:  time.Now(),
-		}, nil
+		}
 	}
+	c.collectDescriptors(pid, metrics)
 	return metrics, nil
 }
 
+// collectDescriptors fills in the open file descriptors of the process and
+// its RLIMIT_NOFILE. Platforms without resource limits leave them at zero.
+func (c *Collector) collectDescriptors(pid int, metrics *types.HandleMetrics) {
+	proc, err := c.process(pid)
+	if err != nil {
+		return
+	}
+	limits, err := proc.RlimitUsage(true)
+	if err != nil {
+		return
+	}
+	for _, limit := range limits {
+		if limit.Resource == process.RLIMIT_NOFILE {
+			metrics.FDs = int(limit.Used)
+			metrics.FDLimit = limit.Soft
+			metrics.FDHardLimit = limit.Hard
+		}
+	}
+}
+
 func (c *Collector) CollectV8(pid int, inspectPort int) (*types.V8Metrics, error) {
 	// Get V8 specific metrics via inspector
 	metrics, err := c.getV8Metrics(inspectPort)
@@ -212,13 +622,22 @@ func (c *Collector) CollectV8(pid int, inspectPort int) (*types.V8Metrics, error
 			Timestamp:          time.Now(),
 		}, nil
 	}
+
+	if c.config.CompareRuntime {
+		// Runtime statistics are best effort and stay zero when they fail
+		err := c.collectRuntimeStats(inspectPort, metrics)
+		if err != nil && !c.runtimeStatsFailing {
+			log.Printf("Warning: Failed to collect runtime statistics: %v", err)
+		}
+		c.runtimeStatsFailing = err != nil
+	}
 	return metrics, nil
 }
 
 // measureEventLoopLag measures actual event loop lag using setTimeout drift
 func (c *Collector) measureEventLoopLag(inspectPort int) (float64, error) {
 	// Use Chrome DevTools Protocol to measure event loop lag
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := c.inspectContext()
 	defer cancel()
 
 	// Connect to V8 inspector
@@ -255,6 +674,112 @@ func (c *Collector) measureEventLoopLag(inspectPort int) (float64, error) {
 	return 0, fmt.Errorf("invalid lag measurement result")
 }
 
+// Timer interval of the injected delay histogram, in milliseconds. Every
+// recorded delay includes it, so it is subtracted to get the lag.
+const lagProbeResolution = 10
+
+// lagProbeScript keeps a monitorEventLoopDelay histogram running in the
+// target and returns its stats (in nanoseconds) since the previous call,
+// or null on the call that installs it.
+var lagProbeScript = fmt.Sprintf(`
+	(function() {
+		let probe = globalThis.__stackpulseLag;
+		if (!probe) {
+			const { monitorEventLoopDelay } = require('perf_hooks');
+			probe = globalThis.__stackpulseLag = monitorEventLoopDelay({ resolution: %d });
+			probe.enable();
+			return null;
+		}
+		if (probe.max === 0) {
+			return null;
+		}
+		const stats = {
+			min: probe.min,
+			max: probe.max,
+			mean: probe.mean,
+			p95: probe.percentile(95),
+		};
+		probe.reset();
+		return stats;
+	})()
+`, lagProbeResolution)
+
+// lagWindow is the event loop delay seen by the probe over one poll, in
+// milliseconds.
+type lagWindow struct {
+	Min, Max, Mean, P95 float64
+}
+
+// readLagProbe reads the delay histogram of the in-target probe, which
+// samples the event loop continuously instead of once per poll. It returns
+// nil until the probe has a full window to report.
+func (c *Collector) readLagProbe(inspectPort int) (*lagWindow, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Evaluate(ctx, lagProbeScript)
+	if err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read event loop delay: %w", err)
+	}
+
+	var stats *struct {
+		Min  float64 `json:"min"`
+		Max  float64 `json:"max"`
+		Mean float64 `json:"mean"`
+		P95  float64 `json:"p95"`
+	}
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse event loop delay: %w", err)
+	}
+	if stats == nil {
+		return nil, nil
+	}
+
+	lag := func(ns float64) float64 {
+		return math.Max(0, ns/1e6-lagProbeResolution)
+	}
+	return &lagWindow{
+		Min:  lag(stats.Min),
+		Max:  lag(stats.Max),
+		Mean: lag(stats.Mean),
+		P95:  lag(stats.P95),
+	}, nil
+}
+
+// summarizeLagWindows combines probe windows of equal length: the mean of
+// their means, the lowest minimum, the highest maximum and, as the delays
+// behind each window are not kept, the 95th percentile of their p95s.
+func summarizeLagWindows(windows []lagWindow) lagWindow {
+	if len(windows) == 0 {
+		return lagWindow{}
+	}
+
+	summary := lagWindow{Min: windows[0].Min, Max: windows[0].Max}
+	p95s := make([]float64, len(windows))
+	for i, w := range windows {
+		summary.Mean += w.Mean
+		summary.Min = math.Min(summary.Min, w.Min)
+		summary.Max = math.Max(summary.Max, w.Max)
+		p95s[i] = w.P95
+	}
+	summary.Mean /= float64(len(windows))
+
+	sort.Float64s(p95s)
+	summary.P95 = p95s[min(int(float64(len(p95s))*0.95), len(p95s)-1)]
+	return summary
+}
+
 func (c *Collector) calculateEventLoopStats() (mean, max, min, p95 float64) {
 	if len(c.eventLoopHist) == 0 {
 		return 0, 0, 0, 0
@@ -307,13 +832,69 @@ func (c *Collector) calculateEventLoopUtilization(currentLag float64) float64 {
 	return math.Min(100, 10+((currentLag-1)*5)) // Scale up for higher lag
 }
 
+// Used when the config leaves InspectTimeout unset
+const defaultInspectTimeout = 2 * time.Second
+
+// inspectTimeout bounds every inspector round trip: discovery, evaluate
+// calls and the retries among them.
+func (c *Collector) inspectTimeout() time.Duration {
+	if c.config.InspectTimeout > 0 {
+		return c.config.InspectTimeout
+	}
+	return defaultInspectTimeout
+}
+
+func (c *Collector) inspectContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.inspectTimeout())
+}
+
+// getInspectorWebSocketURL asks the inspector's /json endpoint for the
+// debugger URL of the target. Node names the address it was reached on, not
+// the one a tunnel or proxy exposes, so only the path is kept; the scheme,
+// address and credentials come from InspectHost.
 func (c *Collector) getInspectorWebSocketURL(inspectPort int) (string, error) {
-	// Get WebSocket URL from inspector
-	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/json", inspectPort))
+	base, err := c.config.InspectorURL(inspectPort)
+	if err != nil {
+		return "", err
+	}
+	if c.discoveryErr != nil && time.Since(c.discoveryFailedAt) < c.inspectTimeout() {
+		return "", c.discoveryErr
+	}
+	discovery := *base
+	discovery.Scheme = "http"
+	if base.Scheme == "wss" {
+		discovery.Scheme = "https"
+	}
+	discovery.Path = "/json"
+
+	// Retry briefly while the inspector starts up; the client sends the
+	// credentials of the URL as basic auth
+	client := &http.Client{Timeout: c.inspectTimeout()}
+	var resp *http.Response
+	for attempt := 0; attempt <= c.config.InspectRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+		resp, err = client.Get(discovery.String())
+		if err == nil {
+			break
+		}
+		// An unreachable host would time out again on every retry
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			break
+		}
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to inspector: %w", err)
+		c.discoveryErr = fmt.Errorf("failed to connect to inspector: %w", err)
+		c.discoveryFailedAt = time.Now()
+		return "", c.discoveryErr
 	}
+	c.discoveryErr = nil
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("inspector discovery failed: %s", resp.Status)
+	}
 
 	var sessions []map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
@@ -328,152 +909,254 @@ func (c *Collector) getInspectorWebSocketURL(inspectPort int) (string, error) {
 	if !ok {
 		return "", fmt.Errorf("no WebSocket URL found")
 	}
-
-	return wsURL, nil
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid WebSocket URL %q: %w", wsURL, err)
+	}
+	u.Scheme, u.User, u.Host = base.Scheme, base.User, base.Host
+	return u.String(), nil
 }
 
 func (c *Collector) executeScript(ctx context.Context, wsURL, script string) (interface{}, error) {
-	// This is a simplified implementation
-	// In production, you'd use a proper WebSocket client for Chrome DevTools Protocol
-	
-	// For now, return a simulated measurement based on system load
-	// This will be replaced with actual CDP implementation
-	proc, err := process.NewProcess(int32(c.config.PID))
-	if err != nil {
-		return 0.0, err
+	var raw json.RawMessage
+	for attempt := 0; ; attempt++ {
+		client, err := c.inspectorClient(ctx, wsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err = client.Evaluate(ctx, script)
+		if err == nil {
+			break
+		}
+		c.resetInspector()
+
+		// Only a dropped session is worth retrying; script errors are final
+		select {
+		case <-client.done:
+			if attempt < c.config.InspectRetries && ctx.Err() == nil {
+				continue
+			}
+		default:
+		}
+		return nil, err
 	}
 
-	cpuPercent, err := proc.CPUPercent()
-	if err != nil {
-		return 0.0, err
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
+	return result, nil
+}
 
-	// Simulate event loop lag based on CPU usage
-	// Higher CPU = higher event loop lag
-	baseLag := 0.5
-	cpuFactor := cpuPercent / 100.0
-	simulatedLag := baseLag + (cpuFactor * 50) // Scale with CPU usage
+// inspectorClient returns a CDP session for wsURL, reusing the open one
+// across polls so stateful domains (like the profiler) survive.
+func (c *Collector) inspectorClient(ctx context.Context, wsURL string) (*cdpClient, error) {
+	if c.cdp != nil {
+		select {
+		case <-c.cdp.done:
+			c.resetInspector()
+		default:
+			if c.cdp.url == wsURL {
+				return c.cdp, nil
+			}
+			c.resetInspector()
+		}
+	}
 
-	return simulatedLag, nil
+	client, err := dialCDP(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+	c.cdp = client
+	return client, nil
 }
 
-func (c *Collector) getThreadPoolMetrics(inspectPort int) (*types.ThreadPoolMetrics, error) {
-	// Simplified implementation - would use CDP in production
-	return &types.ThreadPoolMetrics{
-		QueueSize:    0,
-		PoolSize:     4,
-		ActiveCount:  2,
-		PendingCount: 0,
-		Timestamp:    time.Now(),
-	}, nil
+// Reset forgets what was accumulated from the previous process after the
+// target restarted: GC totals, the event loop window and blocked state, the
+// CPU moving average, network and constructor baselines, and the inspector
+// session, so no figure carries across process lifetimes.
+func (c *Collector) Reset() {
+	c.resetInspector()
+	c.eventLoopHist = c.eventLoopHist[:0]
+	c.lagWindows = c.lagWindows[:0]
+	c.lastEventLoop = time.Time{}
+	c.lagTimeouts, c.blockedSince = 0, time.Time{}
+	c.gcCollectionsTotal, c.gcDurationTotal = 0, 0
+	c.cpuSmoothed, c.cpuSmoothedSet = 0, false
+	c.lastNetwork = nil
+	c.lastConstructorSample = time.Time{}
+	c.constructorCache, c.constructorBaseline = nil, nil
 }
 
-func (c *Collector) getGCMetrics(inspectPort int) (*types.GCMetrics, error) {
-	// Simplified implementation - would use CDP in production
-	return &types.GCMetrics{
-		Collections:      1,
-		Duration:         2.5,
-		HeapSizeBefore:   50 * 1024 * 1024,
-		HeapSizeAfter:    45 * 1024 * 1024,
-		Type:             "minor",
-		Reason:           "allocation_limit",
-		CollectionsTotal: 10,
-		DurationTotal:    25.0,
-		Timestamp:        time.Now(),
-	}, nil
+func (c *Collector) resetInspector() {
+	if c.cdp != nil {
+		c.cdp.Close()
+		c.cdp = nil
+	}
+	c.profilerRunning = false
+	c.gcTrace = nil
 }
 
-func (c *Collector) getHandleMetrics(inspectPort int) (*types.HandleMetrics, error) {
-	// Simplified implementation - would use CDP in production
-	return &types.HandleMetrics{
-		Active:     15,
-		Refs:       8,
-		Timers:     3,
-		TCPSockets: 2,
-		UDPSockets: 0,
-		Files:      2,
-		Timestamp:  time.Now(),
-	}, nil
-}
+// gcObserverScript installs a PerformanceObserver for GC entries on first
+// use and returns the collections recorded since the previous call, then
+// starts counting afresh, so no collection is reported twice. Counts are
+// exact; only the first 1000 entries are kept for their kind and flags. The
+// observer lives in the target so it survives reconnects. Runtimes without
+// GC entries report unsupported instead of an observer that never fires.
+const gcObserverScript = `
+	(function() {
+		let state = globalThis.__stackpulseGC;
+		if (!state) {
+			const { PerformanceObserver } = require('perf_hooks');
+			const supported = PerformanceObserver.supportedEntryTypes;
+			if (supported && !supported.includes('gc')) {
+				return { unsupported: 'no gc entry type' };
+			}
+			state = { entries: [], collections: 0, duration: 0 };
+			const observer = new PerformanceObserver((list) => {
+				for (const entry of list.getEntries()) {
+					const detail = entry.detail || entry;
+					state.collections++;
+					state.duration += entry.duration;
+					if (state.entries.length < 1000) {
+						state.entries.push({ duration: entry.duration, kind: detail.kind, flags: detail.flags });
+					}
+				}
+			});
+			try {
+				observer.observe({ entryTypes: ['gc'] });
+			} catch (err) {
+				return { unsupported: String(err) };
+			}
+			state.observer = observer;
+			globalThis.__stackpulseGC = state;
+		}
+		const result = { entries: state.entries, collections: state.collections, duration: state.duration };
+		state.entries = [];
+		state.collections = 0;
+		state.duration = 0;
+		return result;
+	})()
+`
+
+// perf_hooks GC kinds and the forced flag
+const (
+	perfGCMinor       = 1
+	perfGCMajor       = 4
+	perfGCIncremental = 8
+	perfGCWeakCB      = 16
+	perfGCFlagForced  = 4
+)
+
+// getGCMetrics reads GC activity from a PerformanceObserver injected into
+// the target. perf_hooks reports no heap sizes, so reclaim efficiency is
+// only available with --gc-source trace.
+func (c *Collector) getGCMetrics(inspectPort int) (*types.GCMetrics, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
 
-func (c *Collector) getV8Metrics(inspectPort int) (*types.V8Metrics, error) {
-	// Simplified implementation - would use CDP in production
-	heapSpaces := map[string]uint64{
-		"new_space":     10 * 1024 * 1024,
-		"old_space":     40 * 1024 * 1024,
-		"code_space":    5 * 1024 * 1024,
-		"map_space":     2 * 1024 * 1024,
-		"large_object":  8 * 1024 * 1024,
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
 	}
 
-	return &types.V8Metrics{
-		HeapSpaceUsed:      heapSpaces,
-		HeapSpaceSize:      heapSpaces,
-		HeapSpaceAvailable: heapSpaces,
-		MallocedMemory:     15 * 1024 * 1024,
-		PeakMallocedMemory: 20 * 1024 * 1024,
-		Timestamp:          time.Now(),
-	}, nil
-}
-func (c *Collector) getNodeMemoryInfo(pid int) (*types.MemoryMetrics, error) {
-	// Try to connect to V8 inspector
-	inspectURL := fmt.Sprintf("http://localhost:%d/json", c.config.InspectPort)
-	
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(inspectURL)
+	client, err := c.inspectorClient(ctx, wsURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to V8 inspector: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Get memory usage via Runtime.getHeapUsage
-	memoryInfo, err := c.getHeapUsageFromInspector(c.config.InspectPort)
+	raw, err := client.Evaluate(ctx, gcObserverScript)
 	if err != nil {
-		// Fallback to estimated values
-		return &types.MemoryMetrics{
-			HeapTotal: 50 * 1024 * 1024, // 50MB
-			HeapUsed:  30 * 1024 * 1024, // 30MB
-			External:  5 * 1024 * 1024,  // 5MB
-			Timestamp: time.Now(),
-		}, nil
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read GC entries: %w", err)
+	}
+
+	var result struct {
+		Entries []struct {
+			Duration float64 `json:"duration"`
+			Kind     int     `json:"kind"`
+			Flags    int     `json:"flags"`
+		} `json:"entries"`
+		Collections int     `json:"collections"`
+		Duration    float64 `json:"duration"`
+		Unsupported string  `json:"unsupported"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse GC entries: %w", err)
+	}
+	if result.Unsupported != "" {
+		return nil, fmt.Errorf("GC entries are not available in the target: %s", result.Unsupported)
 	}
 
-	return memoryInfo, nil
+	c.gcCollectionsTotal += result.Collections
+	c.gcDurationTotal += result.Duration
+	metrics := &types.GCMetrics{
+		Type:             "none",
+		Collections:      result.Collections,
+		Duration:         result.Duration,
+		CollectionsTotal: c.gcCollectionsTotal,
+		DurationTotal:    c.gcDurationTotal,
+		Timestamp:        time.Now(),
+	}
+	for _, entry := range result.Entries {
+		metrics.Type = perfGCKind(entry.Kind)
+		metrics.Reason = "allocation"
+		if entry.Flags&perfGCFlagForced != 0 {
+			metrics.Reason = "forced"
+		}
+	}
+	return metrics, nil
+}
+
+func perfGCKind(kind int) string {
+	switch kind {
+	case perfGCMinor:
+		return "minor"
+	case perfGCMajor:
+		return "major"
+	case perfGCIncremental:
+		return "incremental"
+	case perfGCWeakCB:
+		return "weakcb"
+	default:
+		return "unknown"
+	}
 }
 
 func (c *Collector) getHeapUsageFromInspector(inspectPort int) (*types.MemoryMetrics, error) {
-	// Use Chrome DevTools Protocol to get accurate heap usage
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := c.inspectContext()
 	defer cancel()
 
-	// This is a simplified implementation
-	// In production, you'd establish a WebSocket connection and use CDP
-	
-	// For now, make HTTP request to get basic info
-	client := &http.Client{Timeout: 2 * time.Second}
-	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/json/runtime/evaluate", inspectPort))
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Execute process.memoryUsage() via inspector
-	script := "JSON.stringify(process.memoryUsage())"
-	payload := map[string]interface{}{
-		"expression": script,
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Evaluate(ctx, "process.memoryUsage()")
+	if err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read memory usage: %w", err)
 	}
 
-	jsonPayload, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", 
-		fmt.Sprintf("http://localhost:%d/json/runtime/evaluate", inspectPort),
-		bytes.NewBuffer(jsonPayload))
-	req.Header.Set("Content-Type", "application/json")
+	var usage struct {
+		HeapTotal uint64 `json:"heapTotal"`
+		HeapUsed  uint64 `json:"heapUsed"`
+		External  uint64 `json:"external"`
+	}
+	if err := json.Unmarshal(raw, &usage); err != nil {
+		return nil, fmt.Errorf("failed to parse memory usage: %w", err)
+	}
 
-	// This is a mock implementation - real CDP would be more complex
 	return &types.MemoryMetrics{
-		HeapTotal: 60 * 1024 * 1024,
-		HeapUsed:  45 * 1024 * 1024,
-		External:  8 * 1024 * 1024,
+		HeapTotal: usage.HeapTotal,
+		HeapUsed:  usage.HeapUsed,
+		External:  usage.External,
 		Timestamp: time.Now(),
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"stackpulse/internal/config"
+)
+
+func TestFindProcessByPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	c := NewCollector(&config.ServiceConfig{Host: "127.0.0.1"})
+	pid, err := c.FindProcessByPort(port)
+	if err != nil {
+		t.Fatalf("FindProcessByPort(%d): %v", port, err)
+	}
+	if pid != os.Getpid() {
+		t.Errorf("FindProcessByPort(%d) = %d, want %d", port, pid, os.Getpid())
+	}
+
+	// The lookup reads the socket table: nothing may have connected
+	tcp := listener.(*net.TCPListener)
+	tcp.SetDeadline(time.Now().Add(50 * time.Millisecond))
+	if conn, err := tcp.Accept(); err == nil {
+		conn.Close()
+		t.Errorf("FindProcessByPort connected to port %d", port)
+	} else if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("accept: %v", err)
+	}
+}
+
+func TestFindProcessByPortClosed(t *testing.T) {
+	// A port that was just free and is not listened on any more
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	c := NewCollector(&config.ServiceConfig{Host: "127.0.0.1"})
+	if pid, err := c.FindProcessByPort(port); err == nil {
+		t.Errorf("FindProcessByPort(%d) = %d, want an error for a closed port", port, pid)
+	}
+}
+
+// TestHelperProcess is not a test: TestFindProcessByName runs the test
+// binary again to get a process of the same name that is not an ancestor.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("STACKPULSE_HELPER_PROCESS") != "1" {
+		t.Skip("helper process for TestFindProcessByName")
+	}
+	time.Sleep(time.Minute)
+}
+
+func TestFindProcessByName(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := exec.Command(exe, "-test.run=^TestHelperProcess$")
+	helper.Env = append(os.Environ(), "STACKPULSE_HELPER_PROCESS=1")
+	if err := helper.Start(); err != nil {
+		t.Fatalf("start helper: %v", err)
+	}
+	defer func() {
+		helper.Process.Kill()
+		helper.Wait()
+	}()
+
+	// Process names are cut to 15 characters on Linux
+	name := filepath.Base(exe)
+	if len(name) > 15 {
+		name = name[:15]
+	}
+
+	c := NewCollector(&config.ServiceConfig{})
+	pids, err := c.FindProcessByName("^" + regexp.QuoteMeta(name))
+	if err != nil {
+		t.Fatalf("FindProcessByName(%q): %v", name, err)
+	}
+	if !slices.Contains(pids, helper.Process.Pid) {
+		t.Errorf("FindProcessByName(%q) = %v, want it to include the helper %d", name, pids, helper.Process.Pid)
+	}
+	if slices.Contains(pids, os.Getpid()) {
+		t.Errorf("FindProcessByName(%q) = %v, want this process %d left out", name, pids, os.Getpid())
+	}
+}
+
+func TestFindProcessByNameUnknown(t *testing.T) {
+	c := NewCollector(&config.ServiceConfig{})
+	if pids, err := c.FindProcessByName("^stackpulse-no-such-process-[0-9]{6}$"); err == nil {
+		t.Errorf("FindProcessByName of an unknown name = %v, want an error", pids)
+	}
+}
+
+func TestCPUUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		busy    float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{"idle", 0, time.Second, 0},
+		{"half a core", 0.5, time.Second, 50},
+		{"one core over a short poll", 0.1, 100 * time.Millisecond, 100},
+		{"four busy cores", 2, 500 * time.Millisecond, 400},
+		{"zero elapsed", 0.5, 0, 0},
+		{"negative elapsed", 0.5, -time.Second, 0},
+		{"counters went backwards", -0.2, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuUsage(tt.busy, tt.elapsed); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("cpuUsage(%g, %v) = %g, want %g", tt.busy, tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeLagWindows(t *testing.T) {
+	if got := summarizeLagWindows(nil); got != (lagWindow{}) {
+		t.Errorf("summarizeLagWindows(nil) = %+v, want zero", got)
+	}
+
+	var windows []lagWindow
+	for i := 1; i <= 20; i++ {
+		v := float64(i)
+		windows = append(windows, lagWindow{Min: v / 2, Max: v * 3, Mean: v, P95: v * 2})
+	}
+	got := summarizeLagWindows(windows)
+	want := lagWindow{Min: 0.5, Max: 60, Mean: 10.5, P95: 40}
+	if got != want {
+		t.Errorf("summarizeLagWindows = %+v, want %+v", got, want)
+	}
+}
+
+func TestInspectorPortFromArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		port    int
+		enabled bool
+	}{
+		{[]string{"node", "server.js"}, 9229, false},
+		{[]string{"node", "--inspect", "server.js"}, 9229, true},
+		{[]string{"node", "--inspect=9300", "server.js"}, 9300, true},
+		{[]string{"node", "--inspect-brk=0.0.0.0:9301", "server.js"}, 9301, true},
+		{[]string{"node", "--inspect=0.0.0.0", "server.js"}, 9229, true},
+		{[]string{"node", "--inspect-wait=[::1]:9302", "server.js"}, 9302, true},
+		{[]string{"node", "--inspect", "--inspect-port", "9303", "server.js"}, 9303, true},
+		{[]string{"node", "--inspect=9300", "--inspect-port=9304", "server.js"}, 9304, true},
+		{[]string{"node", "--inspect-port=9305", "server.js"}, 9305, false},
+		{[]string{"node", "server.js", "--", "--inspect=9306"}, 9229, false},
+	}
+	for _, tt := range tests {
+		port, ok := inspectorPortFromArgs(tt.args)
+		if port != tt.port || ok != tt.enabled {
+			t.Errorf("inspectorPortFromArgs(%q) = %d, %v, want %d, %v", tt.args, port, ok, tt.port, tt.enabled)
+		}
+	}
+}
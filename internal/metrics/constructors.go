@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Heap snapshots pause the target and can be large, so allow them far more
+// time than regular inspector calls
+const heapSnapshotTimeout = 60 * time.Second
+
+// CollectConstructors reports instance counts and retained sizes for the
+// configured constructors. A heap snapshot is only taken once per
+// TrackInterval; in between the previous figures are returned.
+func (c *Collector) CollectConstructors(inspectPort int) ([]types.ConstructorMetrics, error) {
+	if time.Since(c.lastConstructorSample) < c.config.TrackInterval {
+		return c.constructorCache, nil
+	}
+	c.lastConstructorSample = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), heapSnapshotTimeout)
+	defer cancel()
+
+	snapshot, err := c.takeHeapSnapshot(ctx, inspectPort)
+	if err != nil {
+		return c.constructorCache, err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range c.config.TrackConstructors {
+		names[name] = true
+	}
+	stats := snapshot.constructorStats(names)
+
+	if c.constructorBaseline == nil {
+		c.constructorBaseline = make(map[string]uint64)
+	}
+
+	result := make([]types.ConstructorMetrics, 0, len(c.config.TrackConstructors))
+	for _, name := range c.config.TrackConstructors {
+		m := types.ConstructorMetrics{Name: name}
+		if st, ok := stats[name]; ok {
+			m = *st
+		}
+		m.Timestamp = time.Now()
+
+		// Growth is measured against the first snapshot that saw instances
+		baseline, ok := c.constructorBaseline[name]
+		if !ok && m.RetainedSize > 0 {
+			c.constructorBaseline[name] = m.RetainedSize
+		} else if baseline > 0 {
+			m.GrowthPercent = (float64(m.RetainedSize) - float64(baseline)) / float64(baseline) * 100
+		}
+		result = append(result, m)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].RetainedSize > result[j].RetainedSize
+	})
+	c.constructorCache = result
+	return result, nil
+}
+
+// takeHeapSnapshot streams a full heap snapshot from the inspector and parses it.
+func (c *Collector) takeHeapSnapshot(ctx context.Context, inspectPort int) (*heapSnapshot, error) {
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	off := client.On("HeapProfiler.addHeapSnapshotChunk", func(params json.RawMessage) {
+		var chunk struct {
+			Chunk string `json:"chunk"`
+		}
+		if json.Unmarshal(params, &chunk) == nil {
+			buf.WriteString(chunk.Chunk)
+		}
+	})
+	defer off()
+
+	if err := client.Call(ctx, "HeapProfiler.enable", nil, nil); err != nil {
+		c.resetInspector()
+		return nil, err
+	}
+	params := map[string]interface{}{"reportProgress": false}
+	if err := client.Call(ctx, "HeapProfiler.takeHeapSnapshot", params, nil); err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to take heap snapshot: %w", err)
+	}
+
+	return parseHeapSnapshot(&buf)
+}
@@ -0,0 +1,281 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Docker Engine API socket used when DOCKER_HOST does not name a unix socket
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// Container IDs, or prefixes of them, that can be matched against cgroup
+// paths without catching unrelated processes
+var containerIDPattern = regexp.MustCompile(`^[0-9a-f]{12,64}$`)
+
+// ContainerProcess is the Node.js process of a container as seen from the
+// host. InspectHost and InspectPort reach the container's inspector port
+// from the host; InspectHost is empty when no route to it is known.
+type ContainerProcess struct {
+	PID         int
+	InspectHost string
+	InspectPort int
+}
+
+// dockerContainer holds the fields of a Docker container inspection that
+// locate its process and inspector port.
+type dockerContainer struct {
+	ID    string `json:"Id"`
+	State struct {
+		Running bool `json:"Running"`
+		Pid     int  `json:"Pid"`
+	} `json:"State"`
+	HostConfig struct {
+		NetworkMode string `json:"NetworkMode"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+		Ports     map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// FindContainer resolves a container ID or name to the host PID of its
+// Node.js process, inspectPort being the inspector port inside the
+// container. The Docker Engine API is asked first, which also gives the
+// published port or address of the inspector. Without a Docker socket, or
+// one this user cannot open, processes are matched by the container ID in
+// their cgroups, which covers containerd, CRI-O and Kubernetes pods but
+// needs an ID rather than a name and cannot map the inspector port.
+func (c *Collector) FindContainer(id string, inspectPort int) (*ContainerProcess, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("container monitoring is only supported on Linux")
+	}
+
+	socket := dockerSocket()
+	container, err := c.inspectDockerContainer(socket, id)
+	if err == nil {
+		if !container.State.Running || container.State.Pid <= 0 {
+			return nil, fmt.Errorf("container %s is not running", id)
+		}
+		pids, err := descendants(int32(container.State.Pid))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processes of container %s: %w", id, err)
+		}
+		target := &ContainerProcess{PID: int(nodeProcess(pids))}
+		target.InspectHost, target.InspectPort = container.inspectorAddress(inspectPort)
+		return target, nil
+	}
+
+	// Only a missing, stale or inaccessible socket falls back to cgroups;
+	// the runtime's own answer, such as an unknown container, is final
+	var denied bool
+	switch {
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, syscall.ECONNREFUSED):
+	case errors.Is(err, os.ErrPermission):
+		denied = true
+	default:
+		return nil, err
+	}
+
+	pids, scanErr := containerPIDs(id)
+	if scanErr == nil && len(pids) == 0 {
+		scanErr = fmt.Errorf("no process runs in a cgroup of container %s", id)
+	}
+	if scanErr != nil {
+		if denied {
+			return nil, fmt.Errorf("cannot access the Docker socket %s (permission denied: run as root or as a member of the docker group), and %w", socket, scanErr)
+		}
+		return nil, scanErr
+	}
+	return &ContainerProcess{PID: int(nodeProcess(pids)), InspectPort: inspectPort}, nil
+}
+
+// dockerSocket returns the unix socket of the Docker Engine API.
+func dockerSocket() string {
+	if path, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok && path != "" {
+		return path
+	}
+	return defaultDockerSocket
+}
+
+// inspectDockerContainer fetches the inspection of container id over the
+// Docker Engine API. Failures to reach the socket wrap the dial error, so
+// a missing or unreadable socket can be told apart.
+func (c *Collector) inspectDockerContainer(socket, id string) (*dockerContainer, error) {
+	client := &http.Client{
+		Timeout: c.inspectTimeout(),
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://docker/containers/" + url.PathEscape(id) + "/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Docker at %s: %w", socket, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("no such container: %s", id)
+	default:
+		var body struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &body) != nil || body.Message == "" {
+			body.Message = strings.TrimSpace(string(data))
+		}
+		return nil, fmt.Errorf("docker inspect of %s failed: %s: %s", id, resp.Status, body.Message)
+	}
+
+	var container dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&container); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker inspection of %s: %w", id, err)
+	}
+	return &container, nil
+}
+
+// inspectorAddress returns where the host reaches inspectPort of the
+// container: the port it is published on, the host itself for host
+// networking, or else the container's own address, which needs the
+// inspector bound to all interfaces (--inspect=0.0.0.0). host is empty
+// when the container has no address.
+func (dc *dockerContainer) inspectorAddress(inspectPort int) (string, int) {
+	for _, binding := range dc.NetworkSettings.Ports[fmt.Sprintf("%d/tcp", inspectPort)] {
+		port, err := strconv.Atoi(binding.HostPort)
+		if err != nil {
+			continue
+		}
+		host := binding.HostIP
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "localhost"
+		}
+		return host, port
+	}
+
+	if dc.HostConfig.NetworkMode == "host" {
+		return "localhost", inspectPort
+	}
+	if dc.NetworkSettings.IPAddress != "" {
+		return dc.NetworkSettings.IPAddress, inspectPort
+	}
+	names := make([]string, 0, len(dc.NetworkSettings.Networks))
+	for name := range dc.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ip := dc.NetworkSettings.Networks[name].IPAddress; ip != "" {
+			return ip, inspectPort
+		}
+	}
+	return "", inspectPort
+}
+
+// containerPIDs lists the processes whose cgroup paths name container id.
+func containerPIDs(id string) ([]int32, error) {
+	if !containerIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("cannot resolve container %q without the Docker API: give the container ID (at least 12 hex digits)", id)
+	}
+
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processes: %w", err)
+	}
+
+	var matched []int32
+	for _, pid := range pids {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(data), id) {
+			matched = append(matched, pid)
+		}
+	}
+	return matched, nil
+}
+
+// descendants returns pid and every process below it.
+func descendants(pid int32) ([]int32, error) {
+	pids := []int32{pid}
+	for i := 0; i < len(pids); i++ {
+		proc, err := process.NewProcess(pids[i])
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		children, err := proc.Children()
+		if err != nil {
+			// No children is reported as an error
+			continue
+		}
+		for _, child := range children {
+			pids = append(pids, child.Pid)
+		}
+	}
+	return pids, nil
+}
+
+// nodeProcess picks the Node.js process among the processes of a container:
+// the lowest PID named node whose parent is not a node process of the
+// container too, so a wrapper like npm or tini and the workers of a cluster
+// are passed over. A container without a process named node, for example
+// one that sets process.title, gives its lowest PID.
+func nodeProcess(pids []int32) int32 {
+	sorted := append([]int32(nil), pids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	node := make(map[int32]bool)
+	for _, pid := range sorted {
+		proc, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		if name, err := proc.Name(); err == nil && strings.HasPrefix(name, "node") {
+			node[pid] = true
+		}
+	}
+
+	for _, pid := range sorted {
+		if !node[pid] {
+			continue
+		}
+		proc, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		if ppid, err := proc.Ppid(); err == nil && node[ppid] {
+			continue
+		}
+		return pid
+	}
+	return sorted[0]
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CollectCustom evaluates each user-supplied custom metric expression in the
+// target via the inspector. Expressions must evaluate to a number (or a
+// promise of one). Metrics whose expression fails are omitted.
+func (c *Collector) CollectCustom(inspectPort int) (map[string]float64, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64, len(c.config.CustomMetrics))
+	var lastErr error
+	for name, expression := range c.config.CustomMetrics {
+		raw, err := client.Evaluate(ctx, expression)
+		if err != nil {
+			lastErr = fmt.Errorf("custom metric %s: %w", name, err)
+			continue
+		}
+
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			lastErr = fmt.Errorf("custom metric %s is not a number: %s", name, raw)
+			continue
+		}
+		values[name] = value
+	}
+	return values, lastErr
+}
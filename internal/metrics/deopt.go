@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"stackpulse/internal/types"
+)
+
+// Sampling interval for the deopt profiler, in microseconds
+const deoptSamplingInterval = 1000
+
+type cpuProfile struct {
+	Nodes []struct {
+		DeoptReason string `json:"deoptReason"`
+	} `json:"nodes"`
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+}
+
+type heapCodeStatistics struct {
+	CodeAndMetadataSize     uint64 `json:"code_and_metadata_size"`
+	BytecodeAndMetadataSize uint64 `json:"bytecode_and_metadata_size"`
+}
+
+// collectRuntimeStats fills in deoptimized frames and JIT code statistics.
+// Deoptimized frames are read from the inspector's CPU profiler: the call
+// tree nodes of the last window that carry a deoptReason, i.e. frames V8
+// gave up optimizing. This is not the number of deoptimizations: a frame
+// that deopts repeatedly counts once and every call site of a function
+// counts separately. The profiler is restarted each poll so every window
+// is independent.
+func (c *Collector) collectRuntimeStats(inspectPort int, v8 *types.V8Metrics) error {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return err
+	}
+
+	raw, err := client.Evaluate(ctx, "require('v8').getHeapCodeStatistics()")
+	if err != nil {
+		c.resetInspector()
+		return fmt.Errorf("failed to read code statistics: %w", err)
+	}
+	var codeStats heapCodeStatistics
+	if err := json.Unmarshal(raw, &codeStats); err != nil {
+		return fmt.Errorf("failed to parse code statistics: %w", err)
+	}
+	v8.CodeSize = codeStats.CodeAndMetadataSize
+	v8.BytecodeSize = codeStats.BytecodeAndMetadataSize
+
+	if c.profilerRunning {
+		var result struct {
+			Profile cpuProfile `json:"profile"`
+		}
+		if err := client.Call(ctx, "Profiler.stop", nil, &result); err != nil {
+			c.resetInspector()
+			return err
+		}
+		c.profilerRunning = false
+
+		v8.DeoptCount, v8.TopDeoptReason = countDeopts(&result.Profile)
+		window := (result.Profile.EndTime - result.Profile.StartTime) / 1e6
+		if window > 0 {
+			v8.DeoptRate = float64(v8.DeoptCount) / window
+		}
+	} else {
+		if err := client.Call(ctx, "Profiler.enable", nil, nil); err != nil {
+			return err
+		}
+		params := map[string]interface{}{"interval": deoptSamplingInterval}
+		if err := client.Call(ctx, "Profiler.setSamplingInterval", params, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Call(ctx, "Profiler.start", nil, nil); err != nil {
+		return err
+	}
+	c.profilerRunning = true
+	return nil
+}
+
+// countDeopts returns the number of profile nodes (call sites) marked as
+// deoptimized and the most common reason among them.
+func countDeopts(profile *cpuProfile) (int, string) {
+	reasons := make(map[string]int)
+	count := 0
+	for _, node := range profile.Nodes {
+		if node.DeoptReason == "" {
+			continue
+		}
+		count++
+		reasons[node.DeoptReason]++
+	}
+
+	topReason := ""
+	for reason, n := range reasons {
+		if n > reasons[topReason] || (n == reasons[topReason] && reason < topReason) {
+			topReason = reason
+		}
+	}
+	return count, topReason
+}
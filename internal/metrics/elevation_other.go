@@ -0,0 +1,7 @@
+//go:build !windows
+
+package metrics
+
+// elevationHint is how to see the owners of other users' sockets: the
+// socket table only names processes of the current user without root.
+const elevationHint = "run stackpulse as root (e.g. with sudo)"
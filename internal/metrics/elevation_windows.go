@@ -0,0 +1,7 @@
+//go:build windows
+
+package metrics
+
+// elevationHint is how to see the owners of other users' sockets; on
+// Windows, services run by SYSTEM or another account need an elevated shell.
+const elevationHint = "run stackpulse as administrator"
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// gcTraceCategory is the trace category V8 emits its MinorGC and MajorGC
+// begin/end events under
+const gcTraceCategory = "v8"
+
+type traceEvent struct {
+	Name  string  `json:"name"`
+	Phase string  `json:"ph"`
+	TID   int64   `json:"tid"`
+	TS    float64 `json:"ts"`  // microseconds
+	Dur   float64 `json:"dur"` // microseconds, complete events only
+	Args  struct {
+		Type               string `json:"type"`
+		UsedHeapSizeBefore uint64 `json:"usedHeapSizeBefore"`
+		UsedHeapSizeAfter  uint64 `json:"usedHeapSizeAfter"`
+	} `json:"args"`
+}
+
+// gcEvent is one finished collection taken from the trace.
+type gcEvent struct {
+	major      bool
+	reason     string
+	duration   float64 // milliseconds
+	heapBefore uint64
+	heapAfter  uint64
+}
+
+// gcTracer collects GC events from a NodeTracing session. Node only hands
+// over trace data when tracing stops, and may deliver it after reporting
+// completion, so events are buffered as they arrive and a poll sees what
+// has come in so far; the rest is picked up by the next one.
+type gcTracer struct {
+	client   *cdpClient
+	running  bool
+	complete chan struct{}
+
+	mu     sync.Mutex
+	begins map[int64]traceEvent // open begin events by thread
+	events []gcEvent
+}
+
+func newGCTracer(client *cdpClient) *gcTracer {
+	t := &gcTracer{
+		client:   client,
+		complete: make(chan struct{}, 1),
+		begins:   make(map[int64]traceEvent),
+	}
+	client.On("NodeTracing.dataCollected", t.handleData)
+	client.On("NodeTracing.tracingComplete", func(json.RawMessage) {
+		select {
+		case t.complete <- struct{}{}:
+		default:
+		}
+	})
+	return t
+}
+
+func (t *gcTracer) handleData(params json.RawMessage) {
+	var data struct {
+		Value []traceEvent `json:"value"`
+	}
+	if err := json.Unmarshal(params, &data); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, event := range data.Value {
+		if event.Name != "MinorGC" && event.Name != "MajorGC" {
+			continue
+		}
+
+		switch event.Phase {
+		case "B":
+			t.begins[event.TID] = event
+		case "E":
+			begin, ok := t.begins[event.TID]
+			if !ok || begin.Name != event.Name {
+				continue
+			}
+			delete(t.begins, event.TID)
+			t.events = append(t.events, gcEvent{
+				major:      event.Name == "MajorGC",
+				reason:     begin.Args.Type,
+				duration:   (event.TS - begin.TS) / 1000,
+				heapBefore: begin.Args.UsedHeapSizeBefore,
+				heapAfter:  event.Args.UsedHeapSizeAfter,
+			})
+		case "X":
+			t.events = append(t.events, gcEvent{
+				major:      event.Name == "MajorGC",
+				reason:     event.Args.Type,
+				duration:   event.Dur / 1000,
+				heapBefore: event.Args.UsedHeapSizeBefore,
+				heapAfter:  event.Args.UsedHeapSizeAfter,
+			})
+		}
+	}
+}
+
+// drain returns and forgets the collections received so far.
+func (t *gcTracer) drain() []gcEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.events
+	t.events = nil
+	return events
+}
+
+// collectGCTrace reads GC activity from V8 trace events. Tracing is
+// restarted every poll so Node flushes what it has recorded; a collection
+// can therefore show up one poll after it happened.
+func (c *Collector) collectGCTrace(inspectPort int) (*types.GCMetrics, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+	if c.gcTrace == nil || c.gcTrace.client != client {
+		c.gcTrace = newGCTracer(client)
+	}
+	tracer := c.gcTrace
+
+	if tracer.running {
+		if err := client.Call(ctx, "NodeTracing.stop", nil, nil); err != nil {
+			c.resetInspector()
+			return nil, err
+		}
+		tracer.running = false
+
+		select {
+		case <-tracer.complete:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for trace data: %w", ctx.Err())
+		}
+	}
+
+	params := map[string]interface{}{
+		"traceConfig": map[string]interface{}{
+			"includedCategories": []string{gcTraceCategory},
+		},
+	}
+	if err := client.Call(ctx, "NodeTracing.start", params, nil); err != nil {
+		return nil, fmt.Errorf("failed to start GC tracing: %w", err)
+	}
+	tracer.running = true
+
+	metrics := &types.GCMetrics{
+		Type:      "none",
+		Timestamp: time.Now(),
+	}
+	for _, event := range tracer.drain() {
+		metrics.Collections++
+		metrics.Duration += event.duration
+		metrics.HeapSizeBefore = event.heapBefore
+		metrics.HeapSizeAfter = event.heapAfter
+		metrics.Type = "minor"
+		if event.major {
+			metrics.Type = "major"
+		}
+		metrics.Reason = event.reason
+	}
+
+	c.gcCollectionsTotal += metrics.Collections
+	c.gcDurationTotal += metrics.Duration
+	metrics.CollectionsTotal = c.gcCollectionsTotal
+	metrics.DurationTotal = c.gcDurationTotal
+	return metrics, nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// handlesScript lists the resources keeping the target's event loop busy
+// by type (process.getActiveResourcesInfo, Node.js 17 and later), and
+// counts the active handles that are ref'ed. Older runtimes return null.
+const handlesScript = `
+	(function() {
+		if (typeof process.getActiveResourcesInfo !== 'function') {
+			return null;
+		}
+		const handles = typeof process._getActiveHandles === 'function' ? process._getActiveHandles() : [];
+		const refs = handles.filter(h => {
+			const handle = h && h._handle;
+			return !handle || typeof handle.hasRef !== 'function' || handle.hasRef();
+		}).length;
+		return { resources: process.getActiveResourcesInfo(), refs };
+	})()`
+
+// getHandleMetrics reads the active handles, requests and timers of the
+// target through the inspector.
+func (c *Collector) getHandleMetrics(inspectPort int) (*types.HandleMetrics, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Evaluate(ctx, handlesScript)
+	if err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read active handles: %w", err)
+	}
+
+	var info *struct {
+		Resources []string `json:"resources"`
+		Refs      int      `json:"refs"`
+	}
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse active handles: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("active handles need Node.js 17 or later")
+	}
+
+	metrics := countResources(info.Resources)
+	metrics.Refs = info.Refs
+	metrics.Timestamp = time.Now()
+	return metrics, nil
+}
+
+// countResources sorts the resource types of getActiveResourcesInfo into
+// the handle counts. Active counts every resource, timers included.
+func countResources(resources []string) *types.HandleMetrics {
+	metrics := &types.HandleMetrics{Active: len(resources)}
+	for _, resource := range resources {
+		switch {
+		case resource == "Timeout" || resource == "Immediate":
+			metrics.Timers++
+		case strings.HasPrefix(resource, "TCP"):
+			metrics.TCPSockets++
+		case strings.HasPrefix(resource, "UDP"):
+			metrics.UDPSockets++
+		case strings.HasPrefix(resource, "FS") || strings.HasPrefix(resource, "FileHandle") || resource == "StatWatcher":
+			metrics.Files++
+		}
+	}
+	return metrics
+}
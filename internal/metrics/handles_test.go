@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"stackpulse/internal/types"
+)
+
+func TestCountResources(t *testing.T) {
+	// process.getActiveResourcesInfo() of a Node.js 20 process with a
+	// server, a client connection, a UDP socket, timers and a file read
+	resources := []string{
+		"TCPServerWrap", "UDPWrap", "TCPSocketWrap", "TCPSocketWrap",
+		"FSReqCallback", "FileHandleCloseReq", "TTYWrap",
+		"Timeout", "Timeout", "Immediate",
+	}
+	got := countResources(resources)
+	want := types.HandleMetrics{Active: 10, Timers: 3, TCPSockets: 3, UDPSockets: 1, Files: 2}
+	if *got != want {
+		t.Errorf("countResources = %+v, want %+v", *got, want)
+	}
+
+	if got := countResources(nil); *got != (types.HandleMetrics{}) {
+		t.Errorf("countResources(nil) = %+v, want zero", *got)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	"stackpulse/internal/types"
+)
+
+// HeapConstructors takes a heap snapshot on its own inspector session and
+// aggregates every object constructor in it.
+func (c *Collector) HeapConstructors(ctx context.Context, inspectPort int) (map[string]*types.ConstructorMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, heapSnapshotTimeout)
+	defer cancel()
+
+	snapshot, err := c.takeHeapSnapshot(ctx, inspectPort)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.constructorStats(nil), nil
+}
+
+// DiffConstructors compares two constructor aggregations and returns the
+// constructors that grew, largest retained size growth first (instance
+// count growth breaks ties).
+func DiffConstructors(before, after map[string]*types.ConstructorMetrics) []types.ConstructorDiff {
+	var diffs []types.ConstructorDiff
+	for name, a := range after {
+		diff := types.ConstructorDiff{
+			Name:          name,
+			CountAfter:    a.Count,
+			RetainedAfter: a.RetainedSize,
+		}
+		if b, ok := before[name]; ok {
+			diff.CountBefore = b.Count
+			diff.RetainedBefore = b.RetainedSize
+		}
+		if diff.CountDelta() <= 0 && diff.RetainedDelta() <= 0 {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].RetainedDelta() != diffs[j].RetainedDelta() {
+			return diffs[i].RetainedDelta() > diffs[j].RetainedDelta()
+		}
+		if diffs[i].CountDelta() != diffs[j].CountDelta() {
+			return diffs[i].CountDelta() > diffs[j].CountDelta()
+		}
+		return diffs[i].Name < diffs[j].Name
+	})
+	return diffs
+}
@@ -0,0 +1,304 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"stackpulse/internal/types"
+)
+
+// heapSnapshot is a parsed V8 .heapsnapshot document. Nodes and edges are
+// kept in V8's flat array layout; field offsets come from the snapshot meta.
+type heapSnapshot struct {
+	nodes   []uint64
+	edges   []uint64
+	strings []string
+
+	nodeFieldCount  int
+	nodeTypeOffset  int
+	nodeNameOffset  int
+	nodeSizeOffset  int
+	nodeEdgesOffset int
+	nodeTypes       []string
+
+	edgeFieldCount int
+	edgeTypeOffset int
+	edgeToOffset   int
+	edgeTypes      []string
+}
+
+func parseHeapSnapshot(r io.Reader) (*heapSnapshot, error) {
+	var raw struct {
+		Snapshot struct {
+			Meta struct {
+				NodeFields []string          `json:"node_fields"`
+				NodeTypes  []json.RawMessage `json:"node_types"`
+				EdgeFields []string          `json:"edge_fields"`
+				EdgeTypes  []json.RawMessage `json:"edge_types"`
+			} `json:"meta"`
+		} `json:"snapshot"`
+		Nodes   []uint64 `json:"nodes"`
+		Edges   []uint64 `json:"edges"`
+		Strings []string `json:"strings"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode heap snapshot: %w", err)
+	}
+
+	meta := raw.Snapshot.Meta
+	s := &heapSnapshot{
+		nodes:          raw.Nodes,
+		edges:          raw.Edges,
+		strings:        raw.Strings,
+		nodeFieldCount: len(meta.NodeFields),
+		edgeFieldCount: len(meta.EdgeFields),
+	}
+
+	var err error
+	if s.nodeTypeOffset, err = fieldOffset(meta.NodeFields, "type"); err != nil {
+		return nil, err
+	}
+	if s.nodeNameOffset, err = fieldOffset(meta.NodeFields, "name"); err != nil {
+		return nil, err
+	}
+	if s.nodeSizeOffset, err = fieldOffset(meta.NodeFields, "self_size"); err != nil {
+		return nil, err
+	}
+	if s.nodeEdgesOffset, err = fieldOffset(meta.NodeFields, "edge_count"); err != nil {
+		return nil, err
+	}
+	if s.edgeTypeOffset, err = fieldOffset(meta.EdgeFields, "type"); err != nil {
+		return nil, err
+	}
+	if s.edgeToOffset, err = fieldOffset(meta.EdgeFields, "to_node"); err != nil {
+		return nil, err
+	}
+
+	if len(meta.NodeTypes) == 0 || len(meta.EdgeTypes) == 0 {
+		return nil, fmt.Errorf("heap snapshot meta is missing type tables")
+	}
+	if err := json.Unmarshal(meta.NodeTypes[0], &s.nodeTypes); err != nil {
+		return nil, fmt.Errorf("invalid node types in heap snapshot: %w", err)
+	}
+	if err := json.Unmarshal(meta.EdgeTypes[0], &s.edgeTypes); err != nil {
+		return nil, fmt.Errorf("invalid edge types in heap snapshot: %w", err)
+	}
+
+	if s.nodeFieldCount == 0 || len(s.nodes)%s.nodeFieldCount != 0 {
+		return nil, fmt.Errorf("heap snapshot node array is malformed")
+	}
+	if s.edgeFieldCount == 0 || len(s.edges)%s.edgeFieldCount != 0 {
+		return nil, fmt.Errorf("heap snapshot edge array is malformed")
+	}
+	return s, nil
+}
+
+func fieldOffset(fields []string, name string) (int, error) {
+	for i, field := range fields {
+		if field == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("heap snapshot has no %q field", name)
+}
+
+func (s *heapSnapshot) nodeCount() int {
+	return len(s.nodes) / s.nodeFieldCount
+}
+
+// constructorStats aggregates object instances by constructor name. When
+// names is non-nil only those constructors are reported. Retained sizes come
+// from the dominator tree of the strong (non-weak) reference graph; the
+// per-constructor figure is the sum over instances, so instances retaining
+// each other are counted more than once.
+func (s *heapSnapshot) constructorStats(names map[string]bool) map[string]*types.ConstructorMetrics {
+	n := s.nodeCount()
+	if n == 0 {
+		return map[string]*types.ConstructorMetrics{}
+	}
+
+	// Index of each node's first edge
+	firstEdge := make([]int, n+1)
+	for i := 0; i < n; i++ {
+		firstEdge[i+1] = firstEdge[i] + int(s.nodes[i*s.nodeFieldCount+s.nodeEdgesOffset])
+	}
+
+	weak := -1
+	for i, t := range s.edgeTypes {
+		if t == "weak" {
+			weak = i
+		}
+	}
+	successors := func(node int, fn func(to int)) {
+		for e := firstEdge[node]; e < firstEdge[node+1]; e++ {
+			off := e * s.edgeFieldCount
+			if int(s.edges[off+s.edgeTypeOffset]) == weak {
+				continue
+			}
+			fn(int(s.edges[off+s.edgeToOffset]) / s.nodeFieldCount)
+		}
+	}
+
+	order, postIndex := s.postOrder(successors)
+	idom := s.dominators(order, postIndex, successors)
+
+	retained := make([]uint64, len(order))
+	for po, node := range order {
+		retained[po] = s.nodes[int(node)*s.nodeFieldCount+s.nodeSizeOffset]
+	}
+	root := len(order) - 1
+	for po := 0; po < root; po++ {
+		retained[idom[po]] += retained[po]
+	}
+
+	stats := make(map[string]*types.ConstructorMetrics)
+	for po, node := range order {
+		base := int(node) * s.nodeFieldCount
+		if s.typeName(base) != "object" {
+			continue
+		}
+		name := s.strings[s.nodes[base+s.nodeNameOffset]]
+		if names != nil && !names[name] {
+			continue
+		}
+
+		st, ok := stats[name]
+		if !ok {
+			st = &types.ConstructorMetrics{Name: name}
+			stats[name] = st
+		}
+		st.Count++
+		st.SelfSize += s.nodes[base+s.nodeSizeOffset]
+		st.RetainedSize += retained[po]
+	}
+	return stats
+}
+
+func (s *heapSnapshot) typeName(base int) string {
+	t := int(s.nodes[base+s.nodeTypeOffset])
+	if t < len(s.nodeTypes) {
+		return s.nodeTypes[t]
+	}
+	return ""
+}
+
+// postOrder walks the graph from the root (node 0) and returns reachable
+// nodes in DFS post-order, plus each node's post-order index (-1 when
+// unreachable).
+func (s *heapSnapshot) postOrder(successors func(int, func(int))) ([]int32, []int32) {
+	n := s.nodeCount()
+	postIndex := make([]int32, n)
+	for i := range postIndex {
+		postIndex[i] = -1
+	}
+	visited := make([]bool, n)
+	order := make([]int32, 0, n)
+
+	type frame struct {
+		node     int
+		children []int
+		next     int
+	}
+	childrenOf := func(node int) []int {
+		var children []int
+		successors(node, func(to int) {
+			if !visited[to] {
+				children = append(children, to)
+			}
+		})
+		return children
+	}
+
+	visited[0] = true
+	stack := []frame{{node: 0, children: childrenOf(0)}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(top.children) {
+			child := top.children[top.next]
+			top.next++
+			if !visited[child] {
+				visited[child] = true
+				stack = append(stack, frame{node: child, children: childrenOf(child)})
+			}
+			continue
+		}
+		postIndex[top.node] = int32(len(order))
+		order = append(order, int32(top.node))
+		stack = stack[:len(stack)-1]
+	}
+	return order, postIndex
+}
+
+// dominators computes the immediate dominator of every reachable node using
+// the Cooper-Harvey-Kennedy iterative algorithm. Both input and output are
+// indexed by post-order position; the root is the last entry.
+func (s *heapSnapshot) dominators(order, postIndex []int32, successors func(int, func(int))) []int32 {
+	count := len(order)
+
+	// Predecessor lists in post-order indices
+	predCount := make([]int32, count+1)
+	for _, node := range order {
+		successors(int(node), func(to int) {
+			if postIndex[to] >= 0 {
+				predCount[postIndex[to]+1]++
+			}
+		})
+	}
+	for i := 1; i <= count; i++ {
+		predCount[i] += predCount[i-1]
+	}
+	preds := make([]int32, predCount[count])
+	fill := make([]int32, count)
+	copy(fill, predCount[:count])
+	for po, node := range order {
+		from := int32(po)
+		successors(int(node), func(to int) {
+			if t := postIndex[to]; t >= 0 {
+				preds[fill[t]] = from
+				fill[t]++
+			}
+		})
+	}
+
+	idom := make([]int32, count)
+	for i := range idom {
+		idom[i] = -1
+	}
+	root := int32(count - 1)
+	idom[root] = root
+
+	intersect := func(a, b int32) int32 {
+		for a != b {
+			for a < b {
+				a = idom[a]
+			}
+			for b < a {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for po := root - 1; po >= 0; po-- {
+			newIdom := int32(-1)
+			for _, p := range preds[predCount[po]:predCount[po+1]] {
+				if idom[p] == -1 {
+					continue
+				}
+				if newIdom == -1 {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if newIdom != -1 && idom[po] != newIdom {
+				idom[po] = newIdom
+				changed = true
+			}
+		}
+	}
+	return idom
+}
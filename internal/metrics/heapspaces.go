@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// heapStatisticsScript reads the per-space and whole-heap statistics of the
+// target's V8 isolate in one round trip.
+const heapStatisticsScript = `
+	(function() {
+		const v8 = require('v8');
+		return { spaces: v8.getHeapSpaceStatistics(), heap: v8.getHeapStatistics() };
+	})()`
+
+// heapStatistics is the result of heapStatisticsScript, in the field names of
+// Node's v8 module.
+type heapStatistics struct {
+	Spaces []struct {
+		SpaceName          string `json:"space_name"`
+		SpaceSize          uint64 `json:"space_size"`
+		SpaceUsedSize      uint64 `json:"space_used_size"`
+		SpaceAvailableSize uint64 `json:"space_available_size"`
+	} `json:"spaces"`
+	Heap *struct {
+		MallocedMemory     uint64 `json:"malloced_memory"`
+		PeakMallocedMemory uint64 `json:"peak_malloced_memory"`
+		HeapSizeLimit      uint64 `json:"heap_size_limit"`
+	} `json:"heap"`
+}
+
+// getV8Metrics reads heap space statistics through the inspector. Without a
+// reachable inspector it returns fixed placeholder sizes, so the dashboard
+// keeps its layout for processes started without --inspect, and Sources
+// reports V8 as unavailable.
+func (c *Collector) getV8Metrics(inspectPort int) (*types.V8Metrics, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	c.sources.V8 = false
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return placeholderV8Metrics(), nil
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return placeholderV8Metrics(), nil
+	}
+
+	raw, err := client.Evaluate(ctx, heapStatisticsScript)
+	if err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read heap statistics: %w", err)
+	}
+	metrics, err := parseHeapStatistics(raw)
+	c.sources.V8 = err == nil
+	return metrics, err
+}
+
+// parseHeapStatistics turns the result of heapStatisticsScript into
+// V8Metrics, with the space maps keyed by space_name. A result without the
+// space list or the heap totals is an error rather than zeros.
+func parseHeapStatistics(raw json.RawMessage) (*types.V8Metrics, error) {
+	var stats heapStatistics
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse heap statistics: %w", err)
+	}
+	if len(stats.Spaces) == 0 || stats.Heap == nil {
+		return nil, fmt.Errorf("heap statistics lack the heap spaces or totals")
+	}
+
+	metrics := &types.V8Metrics{
+		HeapSpaceUsed:      make(map[string]uint64, len(stats.Spaces)),
+		HeapSpaceSize:      make(map[string]uint64, len(stats.Spaces)),
+		HeapSpaceAvailable: make(map[string]uint64, len(stats.Spaces)),
+		MallocedMemory:     stats.Heap.MallocedMemory,
+		PeakMallocedMemory: stats.Heap.PeakMallocedMemory,
+		HeapSizeLimit:      stats.Heap.HeapSizeLimit,
+		Timestamp:          time.Now(),
+	}
+	for _, space := range stats.Spaces {
+		metrics.HeapSpaceUsed[space.SpaceName] = space.SpaceUsedSize
+		metrics.HeapSpaceSize[space.SpaceName] = space.SpaceSize
+		metrics.HeapSpaceAvailable[space.SpaceName] = space.SpaceAvailableSize
+	}
+	return metrics, nil
+}
+
+func placeholderV8Metrics() *types.V8Metrics {
+	heapSpaces := map[string]uint64{
+		"new_space":    10 * 1024 * 1024,
+		"old_space":    40 * 1024 * 1024,
+		"code_space":   5 * 1024 * 1024,
+		"map_space":    2 * 1024 * 1024,
+		"large_object": 8 * 1024 * 1024,
+	}
+
+	return &types.V8Metrics{
+		HeapSpaceUsed:      heapSpaces,
+		HeapSpaceSize:      heapSpaces,
+		HeapSpaceAvailable: heapSpaces,
+		MallocedMemory:     15 * 1024 * 1024,
+		PeakMallocedMemory: 20 * 1024 * 1024,
+		Timestamp:          time.Now(),
+	}
+}
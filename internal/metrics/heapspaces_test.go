@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestParseHeapStatistics(t *testing.T) {
+	// Recorded from heapStatisticsScript on Node.js 20
+	raw, err := os.ReadFile("testdata/heap_statistics.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := parseHeapStatistics(raw)
+	if err != nil {
+		t.Fatalf("parseHeapStatistics: %v", err)
+	}
+
+	if len(metrics.HeapSpaceUsed) != 9 {
+		t.Errorf("got %d heap spaces, want 9", len(metrics.HeapSpaceUsed))
+	}
+	spaces := []struct {
+		name                  string
+		used, size, available uint64
+	}{
+		{"new_space", 346784, 1048576, 684096},
+		{"old_space", 2800584, 2850816, 0},
+		{"large_object_space", 262160, 270336, 0},
+	}
+	for _, s := range spaces {
+		if got := metrics.HeapSpaceUsed[s.name]; got != s.used {
+			t.Errorf("%s used = %d, want %d", s.name, got, s.used)
+		}
+		if got := metrics.HeapSpaceSize[s.name]; got != s.size {
+			t.Errorf("%s size = %d, want %d", s.name, got, s.size)
+		}
+		if got := metrics.HeapSpaceAvailable[s.name]; got != s.available {
+			t.Errorf("%s available = %d, want %d", s.name, got, s.available)
+		}
+	}
+	if metrics.MallocedMemory != 262312 {
+		t.Errorf("MallocedMemory = %d, want 262312", metrics.MallocedMemory)
+	}
+	if metrics.PeakMallocedMemory != 106880 {
+		t.Errorf("PeakMallocedMemory = %d, want 106880", metrics.PeakMallocedMemory)
+	}
+	if metrics.HeapSizeLimit != 2197815296 {
+		t.Errorf("HeapSizeLimit = %d, want 2197815296", metrics.HeapSizeLimit)
+	}
+}
+
+func TestParseHeapStatisticsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"malformed", `{"spaces": [{"space_name": "new_space",`},
+		{"wrong type", `{"spaces": "new_space", "heap": {}}`},
+		{"missing heap", `{"spaces": [{"space_name": "new_space", "space_size": 1048576}]}`},
+		{"missing spaces", `{"heap": {"malloced_memory": 262312}}`},
+		{"undefined result", `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if metrics, err := parseHeapStatistics(json.RawMessage(tt.raw)); err == nil {
+				t.Errorf("parseHeapStatistics(%s) = %+v, want an error", tt.raw, metrics)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"stackpulse/internal/types"
+)
+
+// CollectNetwork reports bytes received and sent by the interfaces visible
+// to the process, with rates since the previous call. On Linux this reads
+// /proc/<pid>/net/dev, which covers the process's network namespace: in a
+// container that is effectively the process itself, on a bare host it
+// includes every process sharing the namespace.
+func (c *Collector) CollectNetwork(pid int) (*types.NetworkMetrics, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("network metrics are only supported on Linux")
+	}
+
+	counters, err := net.IOCountersByFile(false, fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network counters: %w", err)
+	}
+	if len(counters) == 0 {
+		return nil, fmt.Errorf("no network counters for process %d", pid)
+	}
+
+	now := time.Now()
+	metrics := &types.NetworkMetrics{
+		BytesRecv: counters[0].BytesRecv,
+		BytesSent: counters[0].BytesSent,
+		Timestamp: now,
+	}
+
+	// Counters can go backwards when interfaces disappear; skip that sample
+	if prev := c.lastNetwork; prev != nil &&
+		metrics.BytesRecv >= prev.BytesRecv && metrics.BytesSent >= prev.BytesSent {
+		elapsed := now.Sub(prev.Timestamp).Seconds()
+		if elapsed > 0 {
+			metrics.RxRate = float64(metrics.BytesRecv-prev.BytesRecv) / elapsed
+			metrics.TxRate = float64(metrics.BytesSent-prev.BytesSent) / elapsed
+		}
+	}
+	c.lastNetwork = metrics
+
+	return metrics, nil
+}
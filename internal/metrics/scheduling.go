@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"stackpulse/internal/types"
+)
+
+// CollectScheduling reads the process's nice value and, on Linux, the
+// kernel's OOM-kill score. OOM fields are -1 where unavailable.
+func (c *Collector) CollectScheduling(pid int) (*types.SchedulingMetrics, error) {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get process %d: %w", pid, err)
+	}
+
+	nice, err := proc.Nice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nice value: %w", err)
+	}
+
+	metrics := &types.SchedulingMetrics{
+		Nice:        nice,
+		OOMScore:    -1,
+		OOMScoreAdj: -1,
+		Timestamp:   time.Now(),
+	}
+
+	if runtime.GOOS == "linux" {
+		if metrics.OOMScore, err = readProcInt(pid, "oom_score"); err != nil {
+			return nil, err
+		}
+		if metrics.OOMScoreAdj, err = readProcInt(pid, "oom_score_adj"); err != nil {
+			return nil, err
+		}
+	}
+
+	return metrics, nil
+}
+
+// readProcMemory reads the swapped-out size of pid from VmSwap in
+// /proc/<pid>/status and its shared resident pages from /proc/<pid>/statm.
+// gopsutil's MemoryInfo reads statm alone and leaves Swap unset on Linux.
+func readProcMemory(pid int) (swap, shared uint64, err error) {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read status: %w", err)
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, ok := strings.CutPrefix(line, "VmSwap:"); ok {
+			kb, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")), 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to parse VmSwap: %w", err)
+			}
+			swap = kb * 1024
+			break
+		}
+	}
+
+	statm, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read statm: %w", err)
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 3 {
+		return 0, 0, fmt.Errorf("failed to parse statm: %q", statm)
+	}
+	pages, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse statm: %w", err)
+	}
+	return swap, pages * uint64(os.Getpagesize()), nil
+}
+
+func readProcInt(pid int, name string) (int, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/%s", pid, name))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"stackpulse/internal/types"
+)
+
+// libuv's thread pool size when UV_THREADPOOL_SIZE is unset, and the most
+// threads it will start however large the variable is
+const (
+	defaultThreadPoolSize = 4
+	maxThreadPoolSize     = 1024
+)
+
+// threadPoolScript installs an async_hooks hook on first use that tracks
+// requests submitted to the libuv thread pool (fs, dns.lookup, and crypto
+// work such as pbkdf2 and scrypt) until their callback runs, and returns how
+// many are in flight along with UV_THREADPOOL_SIZE. libuv does not expose
+// its queue, so requests started before the hook was installed are not
+// counted. The hook lives in the target so it survives reconnects.
+const threadPoolScript = `
+	(function() {
+		let state = globalThis.__stackpulseThreadPool;
+		if (!state) {
+			const pooled = /^(FSREQCALLBACK|FSREQPROMISE|FILEHANDLECLOSEREQ|GETADDRINFOREQWRAP|GETNAMEINFOREQWRAP|\w+REQUEST)$/;
+			state = { inFlight: new Set() };
+			require('async_hooks').createHook({
+				init(id, type) { if (pooled.test(type)) state.inFlight.add(id); },
+				before(id) { state.inFlight.delete(id); },
+				destroy(id) { state.inFlight.delete(id); },
+			}).enable();
+			globalThis.__stackpulseThreadPool = state;
+		}
+		return { inFlight: state.inFlight.size, poolSize: process.env.UV_THREADPOOL_SIZE || '' };
+	})()`
+
+// getThreadPoolMetrics reads the in-flight thread pool requests through the
+// inspector. libuv keeps as many requests running as it has threads and
+// queues the rest, so the active and queued counts are estimates split from
+// the in-flight total at the pool size.
+func (c *Collector) getThreadPoolMetrics(inspectPort int) (*types.ThreadPoolMetrics, error) {
+	ctx, cancel := c.inspectContext()
+	defer cancel()
+
+	wsURL, err := c.getInspectorWebSocketURL(inspectPort)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.inspectorClient(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := client.Evaluate(ctx, threadPoolScript)
+	if err != nil {
+		c.resetInspector()
+		return nil, fmt.Errorf("failed to read thread pool requests: %w", err)
+	}
+
+	var result struct {
+		InFlight int    `json:"inFlight"`
+		PoolSize string `json:"poolSize"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse thread pool requests: %w", err)
+	}
+
+	poolSize := parseThreadPoolSize(result.PoolSize)
+	active := min(result.InFlight, poolSize)
+	return &types.ThreadPoolMetrics{
+		QueueSize:    result.InFlight - active,
+		PoolSize:     poolSize,
+		ActiveCount:  active,
+		PendingCount: result.InFlight,
+		Estimated:    true,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// threadPoolSizeFromEnv returns the pool size from UV_THREADPOOL_SIZE in the
+// environment the process was started with, or libuv's default when it is
+// unset or unreadable.
+func threadPoolSizeFromEnv(pid int) int {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return defaultThreadPoolSize
+	}
+	env, err := proc.Environ()
+	if err != nil {
+		return defaultThreadPoolSize
+	}
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "UV_THREADPOOL_SIZE="); ok {
+			return parseThreadPoolSize(value)
+		}
+	}
+	return defaultThreadPoolSize
+}
+
+// parseThreadPoolSize interprets UV_THREADPOOL_SIZE the way libuv does: an
+// unset or non-positive value means the default and larger values are
+// capped.
+func parseThreadPoolSize(value string) int {
+	size, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || size <= 0 {
+		return defaultThreadPoolSize
+	}
+	return min(size, maxThreadPoolSize)
+}
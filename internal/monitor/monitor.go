@@ -2,33 +2,289 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"stackpulse/internal/config"
+	"stackpulse/internal/diagnostics"
 	"stackpulse/internal/metrics"
 	"stackpulse/internal/display"
 	"stackpulse/internal/alerts"
+	"stackpulse/internal/export"
+	"stackpulse/internal/notify"
+	"stackpulse/internal/store"
 	"stackpulse/internal/types"
 )
 
+// Number of recent statuses kept for diagnostic reports
+const historySize = 120
+
+// maxAnnotations is how many recent annotations are kept on each status
+const maxAnnotations = 5
+
 type Monitor struct {
 	config     *config.ServiceConfig
 	metrics    *metrics.Collector
-	display    *display.Dashboard
+	display    display.Renderer
+	dashboard  *display.Dashboard
+	tui        *display.TUI
 	alerts     *alerts.Manager
+	shm        *export.SharedMemoryWriter
+	jsonl      *export.JSONLWriter
+	csv        *export.CSVWriter
+	influx     *export.InfluxWriter
 	running    bool
 	mu         sync.RWMutex
+
+	// Alert transitions are recorded here: opened by Start from
+	// AlertDBPath, or shared by the caller through RecordAlerts
+	alertDB       *store.AlertDB
+	sharedAlertDB bool
+
+	// collectMu serializes collection cycles, which share collector state
+	collectMu   sync.Mutex
+	subscribers []chan<- types.Status
+
+	// Optional replacement for live collection
+	source MetricSource
+
+	// PID was found by port, so it is looked up again after a restart
+	discovered bool
+
+	// Inspector address as configured, before findContainer maps it to the
+	// container
+	inspectHost  string
+	inspectPort  int
+	inspectSaved bool
+
+	// Latest status and when each metric group was last sampled
+	latest      *types.Status
+	lastSampled map[string]time.Time
+	history     []types.Status
+
+	// statusMu guards latest, history and activeAlerts for readers outside
+	// a collection cycle, so a hung inspector does not block them
+	statusMu     sync.RWMutex
+	activeAlerts []types.Alert
+
+	// Every sample within the configured retention, for history queries
+	samples *store.Store
+
+	// Incident IDs whose diagnostics have already been captured
+	captured map[string]bool
+
+	// Start of the current alert-free streak, for ExitOnRecovery
+	healthySince time.Time
+
+	// Consecutive failed polls, for MaxConsecutiveFailures
+	failures int
+
+	// Set while the process is gone; polls are skipped until retryAt, with
+	// retryDelay growing on every attempt that still finds nothing
+	waiting    bool
+	retryDelay time.Duration
+	retryAt    time.Time
+
+	// Samples since the last summary line, when SummaryEvery is set
+	summary *summary
+
+	// Alert destinations of the current run (set by Start) and the highest
+	// severity already sent per incident
+	notifiers []notify.Notifier
+	notified  map[string]types.AlertSeverity
+
+	// Annotations waiting for the next sample, and those already placed
+	pendingAnnotations []types.Annotation
+	annotations        []types.Annotation
+
+	// Most recent alert transitions, oldest first
+	alertEvents []types.AlertEvent
+
+	// Aggregates since Start, for the report printed on shutdown
+	session types.SessionSummary
 }
 
-func New(cfg *config.ServiceConfig) *Monitor {
+// MetricSource replaces live collection with another producer of statuses,
+// such as the synthetic target used by the demo command.
+type MetricSource interface {
+	Sample(ctx context.Context) (*types.Status, error)
+}
+
+// NewHeadless creates a monitor that never renders to the terminal. This is
+// the entry point for embedding StackPulse as a library: drive it with
+// Collect, or run Start and receive snapshots through Subscribe.
+func NewHeadless(cfg *config.ServiceConfig) *Monitor {
 	return &Monitor{
-		config:  cfg,
-		metrics: metrics.NewCollector(cfg),
-		display: display.NewDashboard(),
-		alerts:  alerts.NewManager(),
+		config:      cfg,
+		metrics:     metrics.NewCollector(cfg),
+		alerts:      alerts.NewManager(),
+		captured:    make(map[string]bool),
+		notified:    make(map[string]types.AlertSeverity),
+		lastSampled: make(map[string]time.Time),
+		samples:     newStore(cfg),
+	}
+}
+
+// newStore creates the sample store with the retention of cfg.
+func newStore(cfg *config.ServiceConfig) *store.Store {
+	capacity, retention := cfg.RetainSamples, cfg.RetainFor
+	if capacity == 0 {
+		capacity = config.DefaultRetainSamples
+	}
+	if retention == 0 {
+		retention = config.DefaultRetainFor
+	}
+	return store.New(capacity, retention)
+}
+
+// NewDashboard creates a dashboard with the display settings of cfg.
+func NewDashboard(cfg *config.ServiceConfig) *display.Dashboard {
+	dashboard := display.NewDashboard()
+	if cfg.RedrawEpsilon > 0 {
+		dashboard.SetThrottle(cfg.RedrawEpsilon, cfg.RedrawMaxInterval)
+	}
+	if glyphs, ok := display.LookupGlyphs(cfg.Glyphs); ok {
+		dashboard.SetGlyphs(glyphs)
+	}
+	if units, ok := display.LookupUnits(cfg.Units); ok {
+		dashboard.SetUnits(units)
+	}
+	if mode, ok := display.LookupColorMode(cfg.Color); ok {
+		display.SetColorMode(mode)
+	}
+	if theme, ok := display.LookupTheme(cfg.Theme); ok {
+		dashboard.SetTheme(theme)
+	}
+	dashboard.SetSparklines(!cfg.NoSparkline)
+	dashboard.SetCollects(cfg.Collects)
+	dashboard.SetCPUThresholds(cfg.CPUThreshold, cfg.CPUCriticalThreshold())
+	dashboard.SetThresholds(cfg.AlertThresholds())
+	if limit, err := cfg.ParseHeapLimit(); err == nil {
+		dashboard.SetMemoryLimit(limit)
+	}
+	return dashboard
+}
+
+// New creates a monitor for the CLI, rendering each snapshot to stdout.
+func New(cfg *config.ServiceConfig) *Monitor {
+	// Redirected output gets plain lines instead of the clearing dashboard
+	dashboard := NewDashboard(cfg)
+
+	m := NewHeadless(cfg)
+	if cfg.Output == config.OutputJSON {
+		// Raw values only: smoothing is for reading, not for pipelines
+		renderer := display.NewJSONRenderer(os.Stdout)
+		renderer.SetPretty(cfg.JSONPretty)
+		m.display = renderer
+		return m
+	}
+	if cfg.Output == config.OutputInflux {
+		m.display = display.NewInfluxRenderer(os.Stdout)
+		return m
+	}
+
+	var renderer display.Renderer = dashboard
+	switch {
+	case cfg.TUI:
+		m.tui = display.NewTUI(dashboard)
+		renderer = m.tui
+	case !display.IsTerminal(os.Stdout):
+		renderer = display.NewLineRenderer(os.Stdout)
+	}
+	if cfg.SmoothSamples > 1 {
+		renderer = display.NewSmoothingRenderer(renderer, cfg.SmoothSamples)
+	}
+
+	m.display = renderer
+	if display.IsTerminal(os.Stdout) && !cfg.TUI {
+		dashboard.SetHistory(m.History)
+		m.dashboard = dashboard
+	}
+	return m
+}
+
+// TUI returns the full-screen display of a monitor created with cfg.TUI,
+// which the caller runs alongside Start, or nil.
+func (m *Monitor) TUI() *display.TUI {
+	return m.tui
+}
+
+// ActiveAlerts returns the alerts of every open incident.
+func (m *Monitor) ActiveAlerts() []types.Alert {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	return append([]types.Alert(nil), m.activeAlerts...)
+}
+
+// Latest returns a copy of the most recent sample, or nil before the first
+// one.
+func (m *Monitor) Latest() *types.Status {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	if m.latest == nil {
+		return nil
+	}
+	latest := *m.latest
+	return &latest
+}
+
+// Samples returns the store of samples within the configured retention. It
+// may be read while the monitor runs.
+func (m *Monitor) Samples() *store.Store {
+	return m.samples
+}
+
+// History returns a copy of the recent samples, oldest first.
+func (m *Monitor) History() []types.Status {
+	m.statusMu.RLock()
+	defer m.statusMu.RUnlock()
+	return append([]types.Status(nil), m.history...)
+}
+
+// NewWithSource creates a CLI monitor that takes its statuses from src
+// instead of a live process. Alerts and rendering work as usual.
+func NewWithSource(cfg *config.ServiceConfig, src MetricSource) *Monitor {
+	m := New(cfg)
+	m.source = src
+	return m
+}
+
+// Subscribe registers ch to receive every status produced by Start. Sends
+// never block: if ch is full the snapshot is dropped for that subscriber.
+func (m *Monitor) Subscribe(ch chan<- types.Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, ch)
+}
+
+// RecordAlerts records the alert transitions of every run in db instead of
+// a database opened from AlertDBPath. db may be shared by several monitors
+// and is not closed when Start returns.
+func (m *Monitor) RecordAlerts(db *store.AlertDB) {
+	m.alertDB = db
+	m.sharedAlertDB = db != nil
+}
+
+// Unsubscribe stops delivery to a channel registered with Subscribe.
+func (m *Monitor) Unsubscribe(ch chan<- types.Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, sub := range m.subscribers {
+		if sub == ch {
+			m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+			return
+		}
 	}
 }
 
@@ -40,162 +296,926 @@ func (m *Monitor) Start(ctx context.Context) error {
 	}
 	m.running = true
 	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+	}()
+
+	m.collectMu.Lock()
+	m.session = types.SessionSummary{Started: time.Now()}
+	m.collectMu.Unlock()
+
+	if err := m.checkPortOwner(); err != nil {
+		return err
+	}
+
+	if m.config.SharedMemoryPath != "" {
+		shm, err := export.NewSharedMemoryWriter(m.config.SharedMemoryPath, export.DefaultSharedMemorySize)
+		if err != nil {
+			return fmt.Errorf("failed to open shared memory output: %w", err)
+		}
+		m.shm = shm
+		defer shm.Close()
+	}
+
+	if m.config.JSONLPath != "" {
+		file, err := export.NewRotatingFile(m.config.JSONLPath, m.config.LogRotateSize, m.config.LogRotateKeep, m.config.LogCompress)
+		if err != nil {
+			return fmt.Errorf("failed to open JSON lines output: %w", err)
+		}
+		m.jsonl = export.NewJSONLWriter(file)
+		defer m.jsonl.Close()
+	}
+
+	if m.config.CSVPath != "" {
+		csv, err := export.NewCSVWriter(m.config.CSVPath)
+		if err != nil {
+			return fmt.Errorf("failed to open CSV output: %w", err)
+		}
+		m.csv = csv
+		defer func() {
+			if err := m.csv.Close(); err != nil {
+				log.Printf("Warning: Failed to close CSV output: %v", err)
+			}
+		}()
+	}
+
+	if m.config.AlertDBPath != "" && !m.sharedAlertDB {
+		db, err := store.OpenAlertDB(m.config.AlertDBPath)
+		if err != nil {
+			return fmt.Errorf("failed to open alert database: %w", err)
+		}
+		m.alertDB = db
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("Warning: Failed to close alert database: %v", err)
+			}
+			m.alertDB = nil
+		}()
+	}
+
+	if m.config.InfluxURL != "" {
+		influx, err := export.NewInfluxWriter(m.config.InfluxURL, m.config.InfluxOrg, m.config.InfluxBucket, m.config.InfluxToken)
+		if err != nil {
+			return fmt.Errorf("failed to start InfluxDB output: %w", err)
+		}
+		m.influx = influx
+		defer m.influx.Close()
+	}
+
+	// Notifiers live for one run: the webhook worker is closed on return,
+	// and a later Start builds them afresh
+	var notifiers []notify.Notifier
+	if m.config.K8sEvents {
+		notifier, err := notify.NewK8sEventsNotifier()
+		if err != nil {
+			return fmt.Errorf("failed to set up Kubernetes events: %w", err)
+		}
+		notifiers = append(notifiers, notify.MinSeverity(notifier, minSeverity(m.config.K8sEventsMinSeverity, types.SeverityCritical)))
+	}
+
+	if m.config.Bell || m.config.DesktopNotify {
+		var out io.Writer = io.Discard
+		if m.config.Bell {
+			out = os.Stderr
+		}
+		notifier := notify.NewBellNotifier(out, m.config.DesktopNotify)
+		notifiers = append(notifiers, notify.MinSeverity(notifier, minSeverity(m.config.BellMinSeverity, types.SeverityInfo)))
+	}
+
+	if m.config.SlackWebhook != "" {
+		notifier := notify.NewSlackNotifier(m.config.SlackWebhook)
+		notifiers = append(notifiers, notify.MinSeverity(notifier, minSeverity(m.config.SlackMinSeverity, types.SeverityWarning)))
+	}
+
+	if m.config.AlertWebhook != "" {
+		notifier := notify.NewWebhookNotifier(m.config.AlertWebhook, m.config.AlertWebhookContentType, m.config.AlertWebhookToken)
+		defer notifier.Close()
+		notifiers = append(notifiers, notifier)
+	}
+
+	if m.config.PagerDutyRoutingKey != "" {
+		notifier := notify.NewPagerDutyNotifier(m.config.PagerDutyRoutingKey, minSeverity(m.config.PagerDutyMinSeverity, types.SeverityCritical))
+		notifiers = append(notifiers, notifier)
+	}
+
+	if m.config.NotifyCooldown > 0 {
+		for i, notifier := range notifiers {
+			notifiers[i] = notify.Cooldown(notifier, m.config.NotifyCooldown)
+		}
+	}
+
+	m.notifiers = notifiers
+	defer func() { m.notifiers = nil }()
 
 	log.Printf("Starting monitor for PID: %d, Host: %s, Port: %d", 
 		m.config.PID, m.config.Host, m.config.Port)
 
+	if m.config.SummaryEvery > 0 {
+		m.collectMu.Lock()
+		m.summary = newSummary()
+		m.collectMu.Unlock()
+		go m.runSummary(ctx)
+	}
+
+	if m.dashboard != nil && display.IsTerminal(os.Stdin) {
+		restore, err := m.dashboard.ListenKeys(ctx, os.Stdin)
+		if err != nil {
+			log.Printf("Warning: Failed to read dashboard keys: %v", err)
+		} else {
+			defer restore()
+		}
+	}
+
+	// Fast polling repaints the dashboard on its own, slower cadence with
+	// the latest sample rather than on every poll
+	var refresh <-chan time.Time
+	if m.dashboard != nil && m.config.RefreshInterval > m.config.PollingInterval {
+		m.dashboard.SetDeferred(true)
+		refreshTicker := time.NewTicker(m.config.RefreshInterval)
+		defer refreshTicker.Stop()
+		refresh = refreshTicker.C
+	}
+
 	ticker := time.NewTicker(m.config.PollingInterval)
 	defer ticker.Stop()
+	tick := ticker.C
+
+	// Aligned polling re-arms a timer for the next wall-clock boundary
+	// after every sample instead of drifting from the start time, and
+	// jittered polling for a randomized interval after the last due time
+	var next func() time.Duration
+	switch {
+	case m.config.PollAlign:
+		next = func() time.Duration { return nextAlignedDelay(time.Now(), m.config.PollingInterval) }
+	case m.config.PollingJitter > 0:
+		due := time.Now()
+		next = func() time.Duration {
+			// An overrunning sample skips ahead rather than bursting to catch up
+			if now := time.Now(); due.Before(now) {
+				due = now
+			}
+			due = due.Add(jitteredInterval(m.config.PollingInterval, m.config.PollingJitter))
+			return time.Until(due)
+		}
+	}
+	var pollTimer *time.Timer
+	if next != nil {
+		ticker.Stop()
+		pollTimer = time.NewTimer(next())
+		defer pollTimer.Stop()
+		tick = pollTimer.C
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			m.mu.Lock()
-			m.running = false
-			m.mu.Unlock()
 			log.Println("Monitor stopped")
 			return nil
-		case <-ticker.C:
-			if err := m.collectAndProcess(); err != nil {
-				log.Printf("Failed to collect metrics: %v", err)
+		case <-refresh:
+			m.dashboard.Refresh()
+		case <-tick:
+			if time.Now().After(m.retryAt) {
+				if stop, err := m.poll(ctx); stop {
+					return err
+				}
+			}
+			if m.config.ExitOnRecovery && !m.healthySince.IsZero() &&
+				time.Since(m.healthySince) >= m.config.RecoveryPeriod {
+				log.Printf("No alerts for %s, service recovered", m.config.RecoveryPeriod)
+				return nil
+			}
+			if pollTimer != nil {
+				pollTimer.Reset(next())
 			}
 		}
 	}
 }
 
-func (m *Monitor) collectAndProcess() error {
-	// Get PID if not specified
-	if m.config.PID == 0 {
-		pid, err := m.metrics.FindProcessByPort(m.config.Port)
-		if err != nil {
-			return fmt.Errorf("failed to find process: %w", err)
+// Longest wait between attempts to find a process that is gone
+const maxRetryDelay = 5 * time.Second
+
+// ExitedError is returned by Collect when the monitored process is no
+// longer running.
+type ExitedError struct {
+	PID int
+}
+
+func (e *ExitedError) Error() string {
+	return fmt.Sprintf("process %d exited", e.PID)
+}
+
+// poll runs one collection cycle and handles its failure. While the process
+// is gone, the failure is logged once and further attempts back off up to
+// maxRetryDelay. It reports whether the monitor should stop, and with what
+// error.
+func (m *Monitor) poll(ctx context.Context) (bool, error) {
+	err := m.collectAndProcess(ctx)
+	if err == nil {
+		if m.waiting {
+			log.Printf("Attached to process %d", m.config.PID)
 		}
-		m.config.PID = pid
+		m.waiting, m.retryDelay, m.retryAt = false, 0, time.Time{}
+		m.failures = 0
+		return false, nil
 	}
+	m.healthySince = time.Time{}
 
-	// Collect all metrics
-	cpuMetrics, err := m.metrics.CollectCPU(m.config.PID)
+	// Discovery leaves the PID unset until it finds the process again
+	var exited *ExitedError
+	if errors.As(err, &exited) || m.config.PID == 0 {
+		if exited != nil && m.config.PID != 0 && !m.config.WaitForProcess {
+			log.Printf("Process %d exited, stopping", exited.PID)
+			return true, nil
+		}
+		if !m.waiting {
+			log.Printf("%v; retrying until it is running", err)
+			m.waiting = true
+		}
+		m.retryDelay = min(max(2*m.retryDelay, m.config.PollingInterval), maxRetryDelay)
+		m.retryAt = time.Now().Add(m.retryDelay)
+	} else {
+		log.Printf("Failed to collect metrics: %v", err)
+	}
+
+	if err := m.handleFailure(ctx, err); err != nil {
+		return true, err
+	}
+	return false, nil
+}
+
+// handleFailure counts a failed poll. Once MaxConsecutiveFailures is reached
+// it runs OnFailureCmd and starts counting again, or without a command
+// returns an error that stops the monitor.
+func (m *Monitor) handleFailure(ctx context.Context, cause error) error {
+	m.failures++
+	if m.config.MaxConsecutiveFailures <= 0 || m.failures < m.config.MaxConsecutiveFailures {
+		return nil
+	}
+
+	failures := m.failures
+	m.failures = 0
+	if m.config.OnFailureCmd == "" {
+		return fmt.Errorf("giving up after %d consecutive failed polls: %w", failures, cause)
+	}
+
+	log.Printf("%d consecutive failed polls, running: %s", failures, m.config.OnFailureCmd)
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", m.config.OnFailureCmd)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", m.config.OnFailureCmd)
+	}
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("STACKPULSE_PID=%d", m.config.PID),
+		fmt.Sprintf("STACKPULSE_FAILURES=%d", failures),
+		"STACKPULSE_ERROR="+cause.Error(),
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Printf("Warning: Failure command failed: %v", err)
+	}
+	return nil
+}
+
+// nextAlignedDelay returns the time until the next instant that is a whole
+// multiple of interval on the wall clock (e.g. every 100ms past the second).
+func nextAlignedDelay(now time.Time, interval time.Duration) time.Duration {
+	return now.Truncate(interval).Add(interval).Sub(now)
+}
+
+// jitteredInterval returns interval moved by a uniformly random amount of
+// up to jitter percent of it either way, and never below 1ms.
+func jitteredInterval(interval time.Duration, jitter float64) time.Duration {
+	offset := (rand.Float64()*2 - 1) * jitter / 100 * float64(interval)
+	return max(interval+time.Duration(offset), time.Millisecond)
+}
+
+func (m *Monitor) collectAndProcess(ctx context.Context) error {
+	status, err := m.Collect(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to collect CPU metrics: %w", err)
+		return err
+	}
+
+	// Update display
+	if m.display != nil {
+		m.display.Update(status)
+	}
+
+	if m.shm != nil {
+		if err := m.shm.Write(status); err != nil {
+			log.Printf("Warning: Failed to publish shared memory status: %v", err)
+		}
+	}
+
+	if m.config.OpenMetricsPath != "" {
+		if err := export.WritePrometheusFile(m.config.OpenMetricsPath, status); err != nil {
+			log.Printf("Warning: Failed to write metrics file: %v", err)
+		}
+	}
+
+	if m.jsonl != nil {
+		if err := m.jsonl.Write(status); err != nil {
+			log.Printf("Warning: Failed to write JSON lines status: %v", err)
+		}
+	}
+
+	if m.csv != nil {
+		if err := m.csv.Write(status); err != nil {
+			log.Printf("Warning: Failed to write CSV row: %v", err)
+		}
+	}
+
+	if m.influx != nil {
+		if err := m.influx.Write(status); err != nil {
+			log.Printf("Warning: Failed to queue InfluxDB point: %v", err)
+		}
+	}
+
+	// Send alerts if any
+	// The TUI lists alerts itself
+	if m.display != nil && m.tui == nil && len(status.Alerts) > 0 {
+		for _, alert := range status.Alerts {
+			log.Printf("ALERT [%s] %s: %s (Value: %.2f, Threshold: %.2f)", 
+				string(alert.Severity), string(alert.Type), alert.Message, 
+				alert.Value, alert.Threshold)
+		}
+	}
+
+	if m.config.CaptureOnCritical {
+		m.captureIncidents(ctx, status.Alerts)
+	}
+
+	if m.alertDB != nil {
+		if err := m.alertDB.Record(status.PID, m.alerts.Events()); err != nil {
+			log.Printf("Warning: Failed to record alert transitions: %v", err)
+		}
 	}
 
-	memoryMetrics, err := m.metrics.CollectMemory(m.config.PID)
+	if len(m.notifiers) > 0 {
+		m.dispatch(ctx, status.Alerts, m.alerts.Events())
+	}
+
+	if len(status.Alerts) > 0 {
+		m.healthySince = time.Time{}
+	} else if m.healthySince.IsZero() {
+		m.healthySince = time.Now()
+	}
+
+	m.publish(status)
+	return nil
+}
+
+// minSeverity returns the configured minimum severity of a notifier, or
+// fallback when none is set.
+func minSeverity(configured, fallback types.AlertSeverity) types.AlertSeverity {
+	if configured == "" {
+		return fallback
+	}
+	return configured
+}
+
+// dispatch sends alerts that opened a new incident or escalated an existing
+// one to every notifier, and the incidents resolved by events to those that
+// are also Resolvers. Delivery runs in the background with a timeout so a
+// slow destination never stalls polling.
+func (m *Monitor) dispatch(ctx context.Context, alertList []types.Alert, events []types.AlertEvent) {
+	var fresh []types.Alert
+	active := make(map[string]bool, len(alertList))
+	for _, alert := range alertList {
+		active[alert.IncidentID] = true
+		if sent, ok := m.notified[alert.IncidentID]; ok && alert.Severity.Rank() <= sent.Rank() {
+			continue
+		}
+		m.notified[alert.IncidentID] = alert.Severity
+		fresh = append(fresh, alert)
+	}
+
+	for id := range m.notified {
+		if !active[id] {
+			delete(m.notified, id)
+		}
+	}
+
+	var resolved []types.Alert
+	for _, event := range events {
+		if event.Resolved {
+			resolved = append(resolved, event.Alert)
+		}
+	}
+
+	if len(fresh) == 0 && len(resolved) == 0 {
+		return
+	}
+	ctx = notify.WithPID(ctx, m.config.PID)
+	for _, n := range m.notifiers {
+		go func(n notify.Notifier) {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			if len(fresh) > 0 {
+				if err := n.Notify(ctx, fresh); err != nil {
+					log.Printf("Warning: Failed to send alert notification: %v", err)
+				}
+			}
+			if r, ok := n.(notify.Resolver); ok && len(resolved) > 0 {
+				if err := r.Resolve(ctx, resolved); err != nil {
+					log.Printf("Warning: Failed to send alert resolution: %v", err)
+				}
+			}
+		}(n)
+	}
+}
+
+// captureIncidents saves diagnostics once per incident, the first time one
+// of its alerts reaches critical severity. Captures run in the background
+// so a long profile does not stall polling.
+func (m *Monitor) captureIncidents(ctx context.Context, alertList []types.Alert) {
+	active := make(map[string]bool, len(alertList))
+	for _, alert := range alertList {
+		active[alert.IncidentID] = true
+		if alert.Severity.Rank() < types.SeverityCritical.Rank() || m.captured[alert.IncidentID] {
+			continue
+		}
+		m.captured[alert.IncidentID] = true
+
+		go m.capture(ctx, alert.IncidentID, alertList, m.History())
+	}
+
+	// Forget incidents that have cleared
+	for id := range m.captured {
+		if !active[id] {
+			delete(m.captured, id)
+		}
+	}
+}
+
+func (m *Monitor) capture(ctx context.Context, incidentID string, alertList []types.Alert, history []types.Status) {
+	for _, kind := range m.config.CaptureTypes {
+		var err error
+		var path string
+		switch kind {
+		case "report":
+			path = filepath.Join(m.config.CaptureDir, incidentID+".report.json")
+			err = m.writeReport(ctx, incidentID, alertList, history, path)
+		case "cpu":
+			path = filepath.Join(m.config.CaptureDir, incidentID+".cpuprofile")
+			err = m.metrics.CaptureCPUProfile(ctx, m.config.InspectPort, m.config.CaptureDuration, path)
+		case "heap":
+			path = filepath.Join(m.config.CaptureDir, incidentID+".heapsnapshot")
+			err = m.metrics.CaptureHeapSnapshot(ctx, m.config.InspectPort, path)
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to capture %s diagnostics for incident %s: %v", kind, incidentID, err)
+			continue
+		}
+		log.Printf("Captured %s diagnostics for incident %s: %s", kind, incidentID, path)
+	}
+}
+
+func (m *Monitor) writeReport(ctx context.Context, incidentID string, alertList []types.Alert, history []types.Status, path string) error {
+	d := &diagnostics.Diagnostics{
+		IncidentID:  incidentID,
+		PID:         m.config.PID,
+		InspectPort: m.config.InspectPort,
+		Collector:   m.metrics,
+		Alerts:      alertList,
+		History:     history,
+	}
+	report, err := d.Collect(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to collect memory metrics: %w", err)
+		return err
+	}
+	return diagnostics.WriteReport(path, report)
+}
+
+func (m *Monitor) publish(status *types.Status) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- *status:
+		default:
+		}
+	}
+}
+
+// checkPortOwner applies the PortMismatch policy when both a PID and a port
+// are configured. The PID always takes precedence; the check only catches a
+// stale PID whose port now belongs to another process.
+func (m *Monitor) checkPortOwner() error {
+	if m.config.PID == 0 || m.config.Port == 0 || m.config.PortMismatch == config.PortMismatchIgnore {
+		return nil
 	}
 
-	eventLoopMetrics, err := m.metrics.CollectEventLoop(m.config.PID, m.config.InspectPort)
+	owns, err := m.metrics.OwnsPort(m.config.PID, m.config.Port)
+	if err == nil && owns {
+		return nil
+	}
+	problem := fmt.Sprintf("PID %d does not own port %d", m.config.PID, m.config.Port)
 	if err != nil {
-		log.Printf("Warning: Failed to collect event loop metrics: %v", err)
-		// Use default values
-		eventLoopMetrics = &types.EventLoopMetrics{
-			Lag:         0,
-			Mean:        0,
-			Max:         0,
-			Min:         0,
-			P95:         0,
-			Utilization: 0,
-			Timestamp:   time.Now(),
+		problem = fmt.Sprintf("could not verify that PID %d owns port %d: %v", m.config.PID, m.config.Port, err)
+	}
+
+	if m.config.PortMismatch == config.PortMismatchError {
+		return fmt.Errorf("%s", problem)
+	}
+	log.Printf("Warning: %s; monitoring PID %d", problem, m.config.PID)
+	return nil
+}
+
+// findProcess resolves the PID to monitor from the configured container,
+// pidfile, port or process name pattern.
+func (m *Monitor) findProcess() (int, error) {
+	if m.config.Container != "" {
+		return m.findContainer()
+	}
+	if m.config.PIDFile != "" {
+		pid, err := config.ReadPidFile(m.config.PIDFile)
+		if err != nil {
+			return 0, err
+		}
+		// A supervisor may not have replaced the file of a crashed process yet
+		if !m.metrics.ProcessRunning(pid) {
+			return 0, fmt.Errorf("process %d from pidfile %s is not running", pid, m.config.PIDFile)
 		}
+		return pid, nil
+	}
+	if m.config.ProcessName == "" {
+		return m.metrics.FindProcessByPort(m.config.Port)
 	}
 
-	threadPoolMetrics, err := m.metrics.CollectThreadPool(m.config.PID)
+	pids, err := m.metrics.FindProcessByName(m.config.ProcessName)
 	if err != nil {
-		log.Printf("Warning: Failed to collect thread pool metrics: %v", err)
-		threadPoolMetrics = &types.ThreadPoolMetrics{
-			QueueSize:    0,
-			PoolSize:     4,
-			ActiveCount:  0,
-			PendingCount: 0,
-			Timestamp:    time.Now(),
+		return 0, err
+	}
+	if len(pids) == 1 {
+		return pids[0], nil
+	}
+	matches := make([]string, len(pids))
+	for i, pid := range pids {
+		matches[i] = strconv.Itoa(pid)
+	}
+	return 0, fmt.Errorf("%d processes match %q (PIDs %s); narrow the pattern, use --pid, or add --all to monitor them all",
+		len(pids), m.config.ProcessName, strings.Join(matches, ", "))
+}
+
+// findContainer resolves the PID of the configured container and points the
+// inspector at the container, unless an inspector host was given. The
+// configured inspector port is the one inside the container, so a restarted
+// container is mapped afresh.
+func (m *Monitor) findContainer() (int, error) {
+	if !m.inspectSaved {
+		m.inspectHost, m.inspectPort, m.inspectSaved = m.config.InspectHost, m.config.InspectPort, true
+	}
+
+	target, err := m.metrics.FindContainer(m.config.Container, m.inspectPort)
+	if err != nil {
+		return 0, err
+	}
+	if m.inspectHost != "" && m.inspectHost != "localhost" {
+		return target.PID, nil
+	}
+	if target.InspectHost == "" {
+		log.Printf("Warning: Failed to find the inspector address of container %s; set --inspect-host or publish port %d", m.config.Container, m.inspectPort)
+		return target.PID, nil
+	}
+	m.config.InspectHost, m.config.InspectPort = target.InspectHost, target.InspectPort
+	return target.PID, nil
+}
+
+// Collect performs one full collection cycle and returns the resulting
+// status with alerts evaluated. It does not render or publish anything.
+func (m *Monitor) Collect(ctx context.Context) (*types.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.collectMu.Lock()
+	defer m.collectMu.Unlock()
+
+	if m.source != nil {
+		status, err := m.source.Sample(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample metric source: %w", err)
 		}
+		status.Alerts = m.alerts.CheckThresholds(status, m.config)
+		m.record(status)
+		return status, nil
+	}
+
+	// Get PID if not specified
+	if m.config.PID == 0 {
+		pid, err := m.findProcess()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find process: %w", err)
+		}
+		m.config.PID = pid
+		m.discovered = true
+	}
+
+	// Groups with their own interval keep the previous sample until due
+	now := time.Now()
+	status := &types.Status{}
+	if m.latest != nil {
+		*status = *m.latest
+	}
+	status.PID = m.config.PID
+	status.Timestamp = now
+	status.Alerts = nil
+
+	if m.groupDue("process", now) {
+		cpuMetrics, err := m.metrics.CollectCPU(m.config.PID)
+		if err != nil && !m.metrics.ProcessRunning(m.config.PID) {
+			pid := m.config.PID
+			if m.discovered {
+				m.config.PID = 0
+			}
+			return nil, &ExitedError{PID: pid}
+		}
+		if err != nil {
+			if m.discovered {
+				// The process may have restarted under a new PID
+				m.config.PID = 0
+			}
+			return nil, fmt.Errorf("failed to collect CPU metrics: %w", err)
+		}
+
+		memoryMetrics, err := m.metrics.CollectMemory(m.config.PID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect memory metrics: %w", err)
+		}
+		status.CPU = *cpuMetrics
+		status.Memory = *memoryMetrics
+
+		startedAt, err := m.metrics.ProcessStartTime(m.config.PID)
+		if err != nil {
+			log.Printf("Warning: Failed to read process start time: %v", err)
+		}
+
+		// A new start time means a new process, whether the PID was reused
+		// or the target was found again after exiting; the groups below
+		// start afresh rather than mixing in the previous one
+		if previous := status.StartedAt; !previous.IsZero() && !startedAt.IsZero() && !startedAt.Equal(previous) {
+			log.Printf("Process restarted: PID %d started at %s (previous process started at %s), resetting cumulative metrics",
+				m.config.PID, startedAt.Format(time.RFC3339), previous.Format(time.RFC3339))
+			m.metrics.Reset()
+		}
+		status.StartedAt = startedAt
+	}
+	if !status.StartedAt.IsZero() {
+		status.Uptime = now.Sub(status.StartedAt).Seconds()
+	}
+
+	if m.config.Collects("eventloop") && m.groupDue("eventloop", now) {
+		eventLoopMetrics, err := m.metrics.CollectEventLoop(m.config.PID, m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect event loop metrics: %v", err)
+			// Use default values
+			eventLoopMetrics = &types.EventLoopMetrics{
+				Lag:         0,
+				Mean:        0,
+				Max:         0,
+				Min:         0,
+				P95:         0,
+				Utilization: 0,
+				Timestamp:   time.Now(),
+			}
+		}
+		status.EventLoop = *eventLoopMetrics
+	}
+
+	if m.config.Collects("threadpool") && m.groupDue("threadpool", now) {
+		threadPoolMetrics, err := m.metrics.CollectThreadPool(m.config.PID)
+		if err != nil {
+			log.Printf("Warning: Failed to collect thread pool metrics: %v", err)
+			threadPoolMetrics = &types.ThreadPoolMetrics{
+				QueueSize:    0,
+				PoolSize:     4,
+				ActiveCount:  0,
+				PendingCount: 0,
+				Timestamp:    time.Now(),
+			}
+		}
+		status.ThreadPool = *threadPoolMetrics
 	}
 
 	// Collect additional Node.js specific metrics
-	gcMetrics, err := m.metrics.CollectGC(m.config.PID, m.config.InspectPort)
-	if err != nil {
-		log.Printf("Warning: Failed to collect GC metrics: %v", err)
-		gcMetrics = &types.GCMetrics{
-			Collections:      0,
-			Duration:         0,
-			HeapSizeBefore:   0,
-			HeapSizeAfter:    0,
-			Type:             "unknown",
-			Reason:           "unknown",
-			CollectionsTotal: 0,
-			DurationTotal:    0,
-			Timestamp:        time.Now(),
+	if m.config.Collects("gc") && m.groupDue("gc", now) {
+		gcMetrics, err := m.metrics.CollectGC(m.config.PID, m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect GC metrics: %v", err)
+			gcMetrics = &types.GCMetrics{
+				Collections:      0,
+				Duration:         0,
+				HeapSizeBefore:   0,
+				HeapSizeAfter:    0,
+				Type:             "unknown",
+				Reason:           "unknown",
+				CollectionsTotal: 0,
+				DurationTotal:    0,
+				Timestamp:        time.Now(),
+			}
 		}
+		status.GC = *gcMetrics
 	}
 
-	handleMetrics, err := m.metrics.CollectHandles(m.config.PID, m.config.InspectPort)
-	if err != nil {
-		log.Printf("Warning: Failed to collect handle metrics: %v", err)
-		handleMetrics = &types.HandleMetrics{
-			Active:     0,
-			Refs:       0,
-			Timers:     0,
-			TCPSockets: 0,
-			UDPSockets: 0,
-			Files:      0,
-			Timestamp:  time.Now(),
+	if m.config.Collects("handles") && m.groupDue("handles", now) {
+		handleMetrics, err := m.metrics.CollectHandles(m.config.PID, m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect handle metrics: %v", err)
+			handleMetrics = &types.HandleMetrics{
+				Active:     0,
+				Refs:       0,
+				Timers:     0,
+				TCPSockets: 0,
+				UDPSockets: 0,
+				Files:      0,
+				Timestamp:  time.Now(),
+			}
 		}
+		status.Handles = *handleMetrics
 	}
 
-	v8Metrics, err := m.metrics.CollectV8(m.config.PID, m.config.InspectPort)
-	if err != nil {
-		log.Printf("Warning: Failed to collect V8 metrics: %v", err)
-		v8Metrics = &types.V8Metrics{
-			HeapSpaceUsed:      make(map[string]uint64),
-			HeapSpaceSize:      make(map[string]uint64),
-			HeapSpaceAvailable: make(map[string]uint64),
-			MallocedMemory:     0,
-			PeakMallocedMemory: 0,
-			Timestamp:          time.Now(),
+	if m.config.Collects("v8") && m.groupDue("v8", now) {
+		v8Metrics, err := m.metrics.CollectV8(m.config.PID, m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect V8 metrics: %v", err)
+			v8Metrics = &types.V8Metrics{
+				HeapSpaceUsed:      make(map[string]uint64),
+				HeapSpaceSize:      make(map[string]uint64),
+				HeapSpaceAvailable: make(map[string]uint64),
+				MallocedMemory:     0,
+				PeakMallocedMemory: 0,
+				Timestamp:          time.Now(),
+			}
 		}
+		status.V8 = *v8Metrics
 	}
 
-	// Create status
-	status := &types.Status{
-		PID:         m.config.PID,
-		CPU:         *cpuMetrics,
-		Memory:      *memoryMetrics,
-		EventLoop:   *eventLoopMetrics,
-		ThreadPool:  *threadPoolMetrics,
-		GC:          *gcMetrics,
-		Handles:     *handleMetrics,
-		V8:          *v8Metrics,
-		Timestamp:   time.Now(),
+	// Constructors follow TrackInterval inside the collector
+	if len(m.config.TrackConstructors) > 0 {
+		constructors, err := m.metrics.CollectConstructors(m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect constructor metrics: %v", err)
+		}
+		status.Constructors = constructors
 	}
 
+	if len(m.config.CustomMetrics) > 0 && m.config.Collects("custom") && m.groupDue("custom", now) {
+		custom, err := m.metrics.CollectCustom(m.config.InspectPort)
+		if err != nil {
+			log.Printf("Warning: Failed to collect custom metrics: %v", err)
+		}
+		status.Custom = custom
+	}
+
+	if m.config.CollectNetwork && m.config.Collects("network") && m.groupDue("network", now) {
+		network, err := m.metrics.CollectNetwork(m.config.PID)
+		if err != nil {
+			log.Printf("Warning: Failed to collect network metrics: %v", err)
+		}
+		status.Network = network
+	}
+
+	if m.config.Collects("scheduling") && m.groupDue("scheduling", now) {
+		schedulingMetrics, err := m.metrics.CollectScheduling(m.config.PID)
+		if err != nil {
+			log.Printf("Warning: Failed to collect scheduling metrics: %v", err)
+		}
+		status.Scheduling = schedulingMetrics
+	}
+
+	// Groups not due keep the availability of their previous sample too
+	status.Sources = m.metrics.Sources()
+	status.InspectorAvailable = status.Sources.Inspector()
+
 	// Check for alerts
 	alertList := m.alerts.CheckThresholds(status, m.config)
 	status.Alerts = alertList
 
-	// Update display
-	m.display.Update(status)
+	m.record(status)
+	return status, nil
+}
 
-	// Send alerts if any
-	if len(alertList) > 0 {
-		for _, alert := range alertList {
-			log.Printf("ALERT [%s] %s: %s (Value: %.2f, Threshold: %.2f)", 
-				string(alert.Severity), string(alert.Type), alert.Message, 
-				alert.Value, alert.Threshold)
+// Annotate marks an external event (e.g. "deploy v1.2.3") at the current
+// time. It is attached to the next sample, together with the key metrics of
+// the sample before it.
+func (m *Monitor) Annotate(text string) types.Annotation {
+	annotation := types.Annotation{Text: text, Timestamp: time.Now()}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pendingAnnotations = append(m.pendingAnnotations, annotation)
+	return annotation
+}
+
+// record keeps status as the latest sample and in the recent history.
+func (m *Monitor) record(status *types.Status) {
+	m.mu.Lock()
+	for _, annotation := range m.pendingAnnotations {
+		if m.latest != nil {
+			annotation.CPUBefore = m.latest.CPU.Percent()
+			annotation.RSSBefore = m.latest.Memory.RSS
+			annotation.LagBefore = m.latest.EventLoop.Lag
 		}
+		m.annotations = append(m.annotations, annotation)
+	}
+	m.pendingAnnotations = nil
+	if len(m.annotations) > maxAnnotations {
+		m.annotations = m.annotations[len(m.annotations)-maxAnnotations:]
 	}
+	status.Annotations = append([]types.Annotation(nil), m.annotations...)
+	m.mu.Unlock()
 
-	return nil
+	m.recordAlertEvents(status)
+	if m.summary != nil {
+		m.summary.add(status)
+	}
+	m.recordSession(status)
+	m.samples.Push(status)
+
+	// The cycle is complete: publish it to ActiveAlerts, Latest and History
+	m.statusMu.Lock()
+	m.latest = status
+	m.history = append(m.history, *status)
+	if len(m.history) > historySize {
+		m.history = m.history[len(m.history)-historySize:]
+	}
+	m.activeAlerts = m.alerts.ActiveAlerts()
+	m.statusMu.Unlock()
+}
+
+// recordAlertEvents adds the incidents opened and resolved by the latest
+// alert check to the bounded alert history, and attaches the history to
+// status.
+func (m *Monitor) recordAlertEvents(status *types.Status) {
+	if m.config.AlertHistory <= 0 {
+		return
+	}
+
+	m.alertEvents = append(m.alertEvents, m.alerts.Events()...)
+	if len(m.alertEvents) > m.config.AlertHistory {
+		m.alertEvents = m.alertEvents[len(m.alertEvents)-m.config.AlertHistory:]
+	}
+	status.AlertEvents = append([]types.AlertEvent(nil), m.alertEvents...)
+}
+
+// recordSession adds status to the session aggregates.
+func (m *Monitor) recordSession(status *types.Status) {
+	s := &m.session
+	s.Samples++
+	for _, event := range m.alerts.Events() {
+		if event.Resolved {
+			continue
+		}
+		if s.Alerts == nil {
+			s.Alerts = make(map[types.AlertType]map[types.AlertSeverity]int)
+		}
+		if s.Alerts[event.Alert.Type] == nil {
+			s.Alerts[event.Alert.Type] = make(map[types.AlertSeverity]int)
+		}
+		s.Alerts[event.Alert.Type][event.Alert.Severity]++
+	}
+	if !status.CPU.FirstSample {
+		s.PeakCPU = max(s.PeakCPU, status.CPU.Percent())
+	}
+	s.PeakRSS = max(s.PeakRSS, status.Memory.RSS)
+	s.PeakLag = max(s.PeakLag, status.EventLoop.Lag)
+	s.GCCollections = status.GC.CollectionsTotal
+	s.GCDuration = status.GC.DurationTotal
 }
 
-func GetCurrentStatus() (*types.Status, error) {
-	// Implementation for getting current status
-	return &types.Status{}, nil
+// Session returns the aggregates of the session since Start.
+func (m *Monitor) Session() types.SessionSummary {
+	m.collectMu.Lock()
+	defer m.collectMu.Unlock()
+
+	session := m.session
+	session.Duration = time.Since(session.Started)
+	session.Alerts = make(map[types.AlertType]map[types.AlertSeverity]int, len(m.session.Alerts))
+	for alertType, severities := range m.session.Alerts {
+		session.Alerts[alertType] = make(map[types.AlertSeverity]int, len(severities))
+		for severity, count := range severities {
+			session.Alerts[alertType][severity] = count
+		}
+	}
+	return session
+}
+
+// groupDue reports whether a metric group should be sampled at now. Groups
+// without a configured interval are sampled every cycle.
+func (m *Monitor) groupDue(group string, now time.Time) bool {
+	interval, ok := m.config.GroupIntervals[group]
+	if !ok || m.latest == nil {
+		m.lastSampled[group] = now
+		return true
+	}
+	if now.Sub(m.lastSampled[group]) < interval {
+		return false
+	}
+	m.lastSampled[group] = now
+	return true
 }
 
-func DisplayStatus(status *types.Status) {
-	// Implementation for displaying status in terminal
-	fmt.Printf("PID: %d\n", status.PID)
-	fmt.Printf("CPU Usage: %.2f%%\n", status.CPU.Usage)
-	fmt.Printf("Memory Usage: %d MB\n", status.Memory.RSS/1024/1024)
-	fmt.Printf("Event Loop Lag: %.2fms\n", status.EventLoop.Lag)
-}
\ No newline at end of file
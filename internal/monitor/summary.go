@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// summaryMetrics are the metrics reported in the periodic summary line, in
+// output order.
+var summaryMetrics = []struct {
+	name   string
+	format string
+	value  func(status *types.Status) (float64, bool)
+}{
+	{"cpu", "%.1f%%", func(s *types.Status) (float64, bool) { return s.CPU.Percent(), !s.CPU.FirstSample }},
+	{"rss", "%.1fMB", func(s *types.Status) (float64, bool) { return float64(s.Memory.RSS) / 1024 / 1024, true }},
+	{"heap", "%.1fMB", func(s *types.Status) (float64, bool) { return float64(s.Memory.HeapUsed) / 1024 / 1024, true }},
+	{"lag", "%.2fms", func(s *types.Status) (float64, bool) { return s.EventLoop.Lag, true }},
+	{"elu", "%.1f%%", func(s *types.Status) (float64, bool) { return s.EventLoop.Utilization, true }},
+}
+
+type rollingStats struct {
+	min, max, sum float64
+	count         int
+}
+
+func (r *rollingStats) add(v float64) {
+	if r.count == 0 {
+		r.min, r.max = v, v
+	}
+	r.min = math.Min(r.min, v)
+	r.max = math.Max(r.max, v)
+	r.sum += v
+	r.count++
+}
+
+// summary accumulates samples between two summary lines. It is kept apart
+// from the history buffer, which may hold less than a summary period at fast
+// polling rates.
+type summary struct {
+	stats   []rollingStats
+	samples int
+	alerts  int
+}
+
+func newSummary() *summary {
+	return &summary{stats: make([]rollingStats, len(summaryMetrics))}
+}
+
+// runSummary writes a summary line to stderr every SummaryEvery until ctx
+// is done.
+func (m *Monitor) runSummary(ctx context.Context) {
+	ticker := time.NewTicker(m.config.SummaryEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.collectMu.Lock()
+			line := m.summary.line(now, m.config.SummaryEvery)
+			m.collectMu.Unlock()
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
+
+func (s *summary) add(status *types.Status) {
+	for i, metric := range summaryMetrics {
+		if value, ok := metric.value(status); ok {
+			s.stats[i].add(value)
+		}
+	}
+	s.samples++
+	if len(status.Alerts) > 0 {
+		s.alerts++
+	}
+}
+
+// line formats min/mean/max of each metric over period and resets the
+// accumulated samples.
+func (s *summary) line(now time.Time, period time.Duration) string {
+	samples := s.samples
+	fields := []string{
+		now.Format("2006-01-02T15:04:05"),
+		fmt.Sprintf("SUMMARY last=%s samples=%d", period, samples),
+	}
+	if samples > 0 {
+		for i, metric := range summaryMetrics {
+			st := s.stats[i]
+			if st.count == 0 {
+				continue
+			}
+			f := metric.format
+			fields = append(fields, fmt.Sprintf("%s="+f+"/"+f+"/"+f,
+				metric.name, st.min, st.sum/float64(st.count), st.max))
+		}
+		fields = append(fields, fmt.Sprintf("alerting=%d/%d", s.alerts, samples))
+	}
+
+	*s = *newSummary()
+	return strings.Join(fields, " ")
+}
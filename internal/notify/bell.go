@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// bellGap separates the repeated bells of a critical alert
+const bellGap = 200 * time.Millisecond
+
+// BellNotifier rings the terminal bell when an alert is raised: once for a
+// warning and three times for critical and above. With desktop enabled it
+// also shows an OS notification where one is available (notify-send on
+// Linux, osascript on macOS).
+type BellNotifier struct {
+	out     io.Writer
+	desktop bool
+}
+
+func NewBellNotifier(out io.Writer, desktop bool) *BellNotifier {
+	return &BellNotifier{out: out, desktop: desktop}
+}
+
+func (b *BellNotifier) Notify(ctx context.Context, alerts []types.Alert) error {
+	worst := alerts[0]
+	for _, alert := range alerts[1:] {
+		if alert.Severity.Rank() > worst.Severity.Rank() {
+			worst = alert
+		}
+	}
+
+	rings := 1
+	if worst.Severity.Rank() >= types.SeverityCritical.Rank() {
+		rings = 3
+	}
+	for i := 0; i < rings; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(bellGap):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if _, err := io.WriteString(b.out, "\a"); err != nil {
+			return fmt.Errorf("failed to ring bell: %w", err)
+		}
+	}
+
+	if !b.desktop {
+		return nil
+	}
+	title := fmt.Sprintf("StackPulse %s alert", worst.Severity)
+	return desktopNotification(ctx, title, worst.Message)
+}
+
+// desktopNotification shows an OS notification. Platforms without a known
+// notification tool are skipped silently.
+func desktopNotification(ctx context.Context, title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil
+		}
+		cmd = exec.CommandContext(ctx, "notify-send", title, message)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			appleScriptString(message), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		return nil
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to show desktop notification: %w", err)
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// cooldownFilter holds back alerts whose key was notified less than period
+// ago, so a condition that flaps or keeps reopening its incident notifies
+// at most once per period.
+type cooldownFilter struct {
+	next   Notifier
+	period time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]sentAlert // by alert type and PID
+}
+
+// sentAlert is the last notification sent for a key.
+type sentAlert struct {
+	at       time.Time
+	severity types.AlertSeverity
+}
+
+// Cooldown wraps n so each alert type of a process notifies at most once
+// per period. An escalation above the severity last sent is delivered
+// within the period; resolutions are always passed on.
+func Cooldown(n Notifier, period time.Duration) Notifier {
+	return &cooldownFilter{
+		next:   n,
+		period: period,
+		now:    time.Now,
+		sent:   make(map[string]sentAlert),
+	}
+}
+
+func (f *cooldownFilter) Notify(ctx context.Context, alerts []types.Alert) error {
+	pid := PIDFrom(ctx)
+	now := f.now()
+
+	f.mu.Lock()
+	for key, last := range f.sent {
+		if now.Sub(last.at) >= f.period {
+			delete(f.sent, key)
+		}
+	}
+
+	// Alerts of one type in the same batch are delivered together
+	var kept []types.Alert
+	batch := make(map[string]sentAlert)
+	for _, alert := range alerts {
+		key := fmt.Sprintf("%s/%d", alert.Type, pid)
+		if last, ok := f.sent[key]; ok && alert.Severity.Rank() <= last.severity.Rank() {
+			continue
+		}
+		if alert.Severity.Rank() > batch[key].severity.Rank() {
+			batch[key] = sentAlert{at: now, severity: alert.Severity}
+		}
+		kept = append(kept, alert)
+	}
+	for key, sent := range batch {
+		f.sent[key] = sent
+	}
+	f.mu.Unlock()
+
+	if len(kept) == 0 {
+		return nil
+	}
+	return f.next.Notify(ctx, kept)
+}
+
+func (f *cooldownFilter) Resolve(ctx context.Context, resolved []types.Alert) error {
+	if r, ok := f.next.(Resolver); ok {
+		return r.Resolve(ctx, resolved)
+	}
+	return nil
+}
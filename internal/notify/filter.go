@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"context"
+
+	"stackpulse/internal/types"
+)
+
+// severityFilter passes on only the alerts at or above a minimum severity.
+type severityFilter struct {
+	next Notifier
+	min  types.AlertSeverity
+}
+
+// MinSeverity wraps n so it only receives alerts of at least min severity.
+// Batches left empty by the filter are not delivered.
+func MinSeverity(n Notifier, min types.AlertSeverity) Notifier {
+	return &severityFilter{next: n, min: min}
+}
+
+func (f *severityFilter) Notify(ctx context.Context, alerts []types.Alert) error {
+	var kept []types.Alert
+	for _, alert := range alerts {
+		if alert.Severity.Rank() >= f.min.Rank() {
+			kept = append(kept, alert)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return f.next.Notify(ctx, kept)
+}
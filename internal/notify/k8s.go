@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Paths of the service account credentials mounted into every pod
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+	namespaceFile     = serviceAccountDir + "/namespace"
+)
+
+// K8sEventsNotifier publishes alerts as Kubernetes Events on the pod
+// StackPulse runs in, so they appear in `kubectl describe pod`. The service
+// account needs permission to create events in its namespace. Wrap it with
+// MinSeverity to limit which alerts become events.
+type K8sEventsNotifier struct {
+	apiURL    string
+	namespace string
+	pod       string
+	podUID    string
+	client    *http.Client
+}
+
+// NewK8sEventsNotifier configures the notifier from the in-cluster
+// environment. The pod is taken from POD_NAME / POD_NAMESPACE / POD_UID
+// (set through the downward API), falling back to the hostname and the
+// service account namespace.
+func NewK8sEventsNotifier() (*K8sEventsNotifier, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running inside a Kubernetes cluster")
+	}
+
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		data, err := os.ReadFile(namespaceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	pod := os.Getenv("POD_NAME")
+	if pod == "" {
+		if pod, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to determine pod name: %w", err)
+		}
+	}
+
+	return &K8sEventsNotifier{
+		apiURL:    "https://" + net.JoinHostPort(host, port),
+		namespace: namespace,
+		pod:       pod,
+		podUID:    os.Getenv("POD_UID"),
+		client: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+func (k *K8sEventsNotifier) Notify(ctx context.Context, alerts []types.Alert) error {
+	for _, alert := range alerts {
+		if err := k.createEvent(ctx, alert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *K8sEventsNotifier) createEvent(ctx context.Context, alert types.Alert) error {
+	// Bound service account tokens rotate, so read it for every request
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	timestamp := alert.Timestamp.UTC().Format(time.RFC3339)
+	event := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Event",
+		"metadata": map[string]interface{}{
+			"generateName": k.pod + ".stackpulse-",
+			"namespace":    k.namespace,
+		},
+		"involvedObject": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"name":       k.pod,
+			"namespace":  k.namespace,
+			"uid":        k.podUID,
+		},
+		"reason":             eventReason(alert.Type),
+		"message":            fmt.Sprintf("[%s] %s", alert.Severity, alert.Message),
+		"type":               "Warning",
+		"source":             map[string]interface{}{"component": "stackpulse"},
+		"reportingComponent": "stackpulse",
+		"reportingInstance":  k.pod,
+		"firstTimestamp":     timestamp,
+		"lastTimestamp":      timestamp,
+		"count":              1,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/namespaces/%s/events", k.apiURL, k.namespace)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create event request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Kubernetes event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kubernetes API rejected event: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// eventReason turns an alert type such as "gc_pressure" into an UpperCamel
+// event reason such as "GcPressureAlert".
+func eventReason(alertType types.AlertType) string {
+	var reason strings.Builder
+	for _, part := range strings.Split(string(alertType), "_") {
+		if part == "" {
+			continue
+		}
+		reason.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	reason.WriteString("Alert")
+	return reason.String()
+}
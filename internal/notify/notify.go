@@ -0,0 +1,34 @@
+// Package notify delivers alerts to external destinations.
+package notify
+
+import (
+	"context"
+
+	"stackpulse/internal/types"
+)
+
+// Notifier sends a batch of newly raised alerts to one destination.
+type Notifier interface {
+	Notify(ctx context.Context, alerts []types.Alert) error
+}
+
+// Resolver is implemented by notifiers that also report when an incident
+// closes. resolved holds the last alert of each closed incident, at
+// SeverityInfo.
+type Resolver interface {
+	Resolve(ctx context.Context, resolved []types.Alert) error
+}
+
+type pidKey struct{}
+
+// WithPID records the PID of the monitored process in ctx, for notifiers
+// that report it alongside the alerts.
+func WithPID(ctx context.Context, pid int) context.Context {
+	return context.WithValue(ctx, pidKey{}, pid)
+}
+
+// PIDFrom returns the PID recorded by WithPID, or 0.
+func PIDFrom(ctx context.Context) int {
+	pid, _ := ctx.Value(pidKey{}).(int)
+	return pid
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Delivery attempts per event and the wait before the first retry, doubled
+// for each one after it unless PagerDuty asks for longer
+const (
+	pagerDutyAttempts = 3
+	pagerDutyBackoff  = time.Second
+)
+
+// pagerDutyEvent is an Events API v2 request body.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Component     string                 `json:"component"`
+	Class         string                 `json:"class"`
+	CustomDetails map[string]interface{} `json:"custom_details"`
+}
+
+// PagerDutyNotifier triggers a PagerDuty incident for each alert of at
+// least its minimum severity and resolves it when the alert's incident
+// closes. Events are deduplicated by host, PID and alert type, so repeated
+// and escalated alerts of one type update a single PagerDuty incident.
+// Rate limiting and server errors are retried with backoff.
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string
+	min        types.AlertSeverity
+	hostname   string
+	client     *http.Client
+
+	// Dedup keys triggered and not yet resolved
+	mu        sync.Mutex
+	triggered map[string]bool
+}
+
+// NewPagerDutyNotifier sends events with routingKey, the integration key of
+// an Events API v2 service, for alerts of at least min severity.
+func NewPagerDutyNotifier(routingKey string, min types.AlertSeverity) *PagerDutyNotifier {
+	host, _ := os.Hostname()
+	return &PagerDutyNotifier{
+		url:        pagerDutyEventsURL,
+		routingKey: routingKey,
+		min:        min,
+		hostname:   host,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		triggered:  make(map[string]bool),
+	}
+}
+
+// Notify sends a trigger event for each alert of at least the minimum
+// severity. The PID is taken from ctx (see WithPID).
+func (p *PagerDutyNotifier) Notify(ctx context.Context, alerts []types.Alert) error {
+	pid := PIDFrom(ctx)
+	for _, alert := range alerts {
+		if alert.Severity.Rank() < p.min.Rank() {
+			continue
+		}
+
+		key := p.dedupKey(pid, alert.Type)
+		event := pagerDutyEvent{
+			RoutingKey:  p.routingKey,
+			EventAction: "trigger",
+			DedupKey:    key,
+			Payload: &pagerDutyPayload{
+				Summary:   alert.Message,
+				Source:    p.hostname,
+				Severity:  pagerDutySeverity(alert.Severity),
+				Timestamp: alert.Timestamp.Format(time.RFC3339),
+				Component: fmt.Sprintf("pid %d", pid),
+				Class:     string(alert.Type),
+				CustomDetails: map[string]interface{}{
+					"incident":  alert.IncidentID,
+					"value":     alert.Value,
+					"threshold": alert.Threshold,
+				},
+			},
+		}
+		if err := p.send(ctx, event); err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		p.triggered[key] = true
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// Resolve sends a resolve event for each resolved alert whose type was
+// triggered for this PID.
+func (p *PagerDutyNotifier) Resolve(ctx context.Context, resolved []types.Alert) error {
+	pid := PIDFrom(ctx)
+	for _, alert := range resolved {
+		key := p.dedupKey(pid, alert.Type)
+		p.mu.Lock()
+		triggered := p.triggered[key]
+		p.mu.Unlock()
+		if !triggered {
+			continue
+		}
+
+		event := pagerDutyEvent{RoutingKey: p.routingKey, EventAction: "resolve", DedupKey: key}
+		if err := p.send(ctx, event); err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+		delete(p.triggered, key)
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+func (p *PagerDutyNotifier) dedupKey(pid int, alertType types.AlertType) string {
+	return fmt.Sprintf("stackpulse/%s/%d/%s", p.hostname, pid, alertType)
+}
+
+// send delivers one event, retrying rate limiting and server errors.
+func (p *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty event: %w", err)
+	}
+
+	backoff := pagerDutyBackoff
+	for attempt := 1; ; attempt++ {
+		wait, err := p.post(ctx, body)
+		if err == nil || wait < 0 || attempt == pagerDutyAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(max(wait, backoff)):
+		case <-ctx.Done():
+			return fmt.Errorf("gave up on PagerDuty event: %w", err)
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one attempt. On failure it returns how long to wait before a
+// retry: 0 for the default backoff, longer when PagerDuty sets Retry-After,
+// and negative when the event should not be retried.
+func (p *PagerDutyNotifier) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create PagerDuty request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, fmt.Errorf("failed to post to PagerDuty: %w", err)
+		}
+		return 0, fmt.Errorf("failed to post to PagerDuty: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Accepted events are queued by PagerDuty with 202
+	if resp.StatusCode/100 == 2 {
+		return 0, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("PagerDuty rejected event: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second, err
+		}
+		return 0, err
+	case resp.StatusCode >= 500:
+		return 0, err
+	}
+	return -1, err
+}
+
+// pagerDutySeverity maps an alert severity to a PagerDuty event severity.
+func pagerDutySeverity(severity types.AlertSeverity) string {
+	switch severity {
+	case types.SeverityEmergency, types.SeverityCritical:
+		return "critical"
+	case types.SeverityWarning:
+		return "warning"
+	}
+	return "info"
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Delivery attempts per batch and the wait before the first retry, doubled
+// for each one after it
+const (
+	slackAttempts = 3
+	slackBackoff  = 500 * time.Millisecond
+)
+
+// SlackNotifier posts alerts to a Slack incoming webhook, one attachment per
+// alert colored by severity. Server errors and rate limiting are retried
+// with backoff; other failures are returned at once.
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color    string       `json:"color"`
+	Fallback string       `json:"fallback"`
+	Title    string       `json:"title"`
+	Text     string       `json:"text"`
+	Fields   []slackField `json:"fields"`
+	Footer   string       `json:"footer"`
+	TS       int64        `json:"ts"`
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, alerts []types.Alert) error {
+	host, _ := os.Hostname()
+	attachments := make([]slackAttachment, len(alerts))
+	for i, alert := range alerts {
+		attachments[i] = slackAttachment{
+			Color:    slackColor(alert.Severity),
+			Fallback: fmt.Sprintf("[%s] %s", alert.Severity, alert.Message),
+			Title:    fmt.Sprintf("%s alert", strings.ToUpper(string(alert.Severity))),
+			Text:     alert.Message,
+			Fields: []slackField{
+				{Title: "Type", Value: string(alert.Type), Short: true},
+				{Title: "Incident", Value: alert.IncidentID, Short: true},
+				{Title: "Value", Value: fmt.Sprintf("%.2f", alert.Value), Short: true},
+				{Title: "Threshold", Value: fmt.Sprintf("%.2f", alert.Threshold), Short: true},
+			},
+			Footer: "stackpulse on " + host,
+			TS:     alert.Timestamp.Unix(),
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"attachments": attachments})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	backoff := slackBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil || !retry || attempt == slackAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("gave up on Slack notification: %w", err)
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying.
+func (s *SlackNotifier) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("slack rejected notification: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return false, nil
+}
+
+// slackColor is the attachment bar color of a severity.
+func slackColor(severity types.AlertSeverity) string {
+	switch severity {
+	case types.SeverityEmergency:
+		return "#8b0000"
+	case types.SeverityCritical:
+		return "danger"
+	case types.SeverityWarning:
+		return "warning"
+	}
+	return "#439fe0"
+}
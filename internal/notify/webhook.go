@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// Batches waiting for the webhook worker; further batches are dropped
+const webhookQueueSize = 64
+
+// webhookPayload is the JSON body posted for each batch of alerts.
+type webhookPayload struct {
+	PID      int           `json:"pid"`
+	Hostname string        `json:"hostname"`
+	Alerts   []types.Alert `json:"alerts"`
+}
+
+// WebhookNotifier posts each batch of alerts as JSON to a URL. Batches are
+// queued for a single background worker, so a slow endpoint never holds up
+// the caller; when the queue is full the batch is dropped and counted.
+type WebhookNotifier struct {
+	url         string
+	contentType string
+	token       string
+	hostname    string
+	client      *http.Client
+
+	queue   chan webhookPayload
+	dropped atomic.Uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhookNotifier starts the delivery worker; Close stops it. An empty
+// contentType means application/json, and token, when set, is sent as a
+// bearer token.
+func NewWebhookNotifier(url, contentType, token string) *WebhookNotifier {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	host, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &WebhookNotifier{
+		url:         url,
+		contentType: contentType,
+		token:       token,
+		hostname:    host,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		queue:       make(chan webhookPayload, webhookQueueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Notify queues alerts for delivery and returns at once. The PID is taken
+// from ctx (see WithPID).
+func (w *WebhookNotifier) Notify(ctx context.Context, alerts []types.Alert) error {
+	payload := webhookPayload{PID: PIDFrom(ctx), Hostname: w.hostname, Alerts: alerts}
+	select {
+	case w.queue <- payload:
+		return nil
+	default:
+		dropped := w.dropped.Add(uint64(len(alerts)))
+		return fmt.Errorf("webhook queue full: dropped %d alerts (%d in total)", len(alerts), dropped)
+	}
+}
+
+// Dropped returns the number of alerts dropped because the queue was full.
+func (w *WebhookNotifier) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops the worker, abandoning any batches still queued.
+func (w *WebhookNotifier) Close() error {
+	w.cancel()
+	<-w.done
+	return nil
+}
+
+func (w *WebhookNotifier) run() {
+	defer close(w.done)
+	for {
+		select {
+		case payload := <-w.queue:
+			if err := w.post(payload); err != nil && w.ctx.Err() == nil {
+				log.Printf("Warning: Failed to send alert webhook: %v", err)
+			}
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *WebhookNotifier) post(payload webhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alerts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook rejected alerts: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
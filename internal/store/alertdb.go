@@ -0,0 +1,127 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"stackpulse/internal/types"
+)
+
+// alertSchema is created when an alert database is opened. Timestamps are
+// Unix milliseconds, so they compare and sort as numbers.
+const alertSchema = `
+CREATE TABLE IF NOT EXISTS alert_transitions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp   INTEGER NOT NULL,
+	pid         INTEGER NOT NULL,
+	type        TEXT    NOT NULL,
+	severity    TEXT    NOT NULL,
+	resolved    INTEGER NOT NULL,
+	value       REAL    NOT NULL,
+	threshold   REAL    NOT NULL,
+	message     TEXT    NOT NULL,
+	incident_id TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS alert_transitions_timestamp ON alert_transitions (timestamp);`
+
+// AlertTransition is one alert raised or resolved, as stored in an AlertDB.
+type AlertTransition struct {
+	Timestamp  time.Time           `json:"timestamp"`
+	PID        int                 `json:"pid"`
+	Type       types.AlertType     `json:"type"`
+	Severity   types.AlertSeverity `json:"severity"`
+	Resolved   bool                `json:"resolved"`
+	Value      float64             `json:"value"`
+	Threshold  float64             `json:"threshold"`
+	Message    string              `json:"message"`
+	IncidentID string              `json:"incidentId"`
+}
+
+// AlertDB records alert transitions in a SQLite file. Every statement goes
+// through a single connection, so monitors sharing an AlertDB write one at
+// a time; other processes writing the same file wait on SQLite's lock for
+// up to 5 seconds.
+type AlertDB struct {
+	db *sql.DB
+}
+
+// OpenAlertDB opens or creates the SQLite file at path and creates the
+// alert schema if it is missing.
+func OpenAlertDB(path string) (*AlertDB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(alertSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create alert schema in %s: %w", path, err)
+	}
+	return &AlertDB{db: db}, nil
+}
+
+// Record stores the transitions of one check of the process pid in a
+// single transaction.
+func (a *AlertDB) Record(pid int, events []types.AlertEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO alert_transitions
+		(timestamp, pid, type, severity, resolved, value, threshold, message, incident_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		alert := event.Alert
+		if _, err := stmt.Exec(event.Timestamp.UnixMilli(), pid, string(alert.Type), string(alert.Severity),
+			event.Resolved, alert.Value, alert.Threshold, alert.Message, alert.IncidentID); err != nil {
+			return fmt.Errorf("failed to insert alert transition: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Since returns the transitions recorded at or after since, oldest first.
+func (a *AlertDB) Since(since time.Time) ([]AlertTransition, error) {
+	rows, err := a.db.Query(`SELECT timestamp, pid, type, severity, resolved, value, threshold, message, incident_id
+		FROM alert_transitions WHERE timestamp >= ? ORDER BY timestamp, id`, since.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []AlertTransition
+	for rows.Next() {
+		var t AlertTransition
+		var millis int64
+		if err := rows.Scan(&millis, &t.PID, &t.Type, &t.Severity, &t.Resolved,
+			&t.Value, &t.Threshold, &t.Message, &t.IncidentID); err != nil {
+			return nil, fmt.Errorf("failed to read alert transition: %w", err)
+		}
+		t.Timestamp = time.UnixMilli(millis)
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert transitions: %w", err)
+	}
+	return transitions, nil
+}
+
+// Close closes the database.
+func (a *AlertDB) Close() error {
+	return a.db.Close()
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+func TestAlertDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.sqlite")
+	db, err := OpenAlertDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+	raised := types.Alert{Type: types.AlertTypeCPU, Severity: types.SeverityCritical, Value: 95, Threshold: 70, IncidentID: "cpu-1"}
+	resolved := raised
+	resolved.Severity = types.SeverityInfo
+	old := types.AlertEvent{Alert: raised, Timestamp: now.Add(-2 * time.Hour)}
+
+	// Monitors sharing the database record concurrently
+	var wg sync.WaitGroup
+	for pid := 1; pid <= 4; pid++ {
+		wg.Add(1)
+		go func(pid int) {
+			defer wg.Done()
+			events := []types.AlertEvent{
+				{Alert: raised, Timestamp: now},
+				{Alert: resolved, Resolved: true, Timestamp: now.Add(time.Second)},
+			}
+			if err := db.Record(pid, events); err != nil {
+				t.Error(err)
+			}
+		}(pid)
+	}
+	wg.Wait()
+	if err := db.Record(1, []types.AlertEvent{old}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Reopening keeps the schema and the rows
+	db, err = OpenAlertDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	got, err := db.Since(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 8 {
+		t.Fatalf("Since returned %d transitions, want 8", len(got))
+	}
+	for i, transition := range got {
+		wantResolved := i >= 4
+		if transition.Resolved != wantResolved || !transition.Timestamp.Equal(now.Add(time.Duration(i/4)*time.Second)) {
+			t.Errorf("transition %d = %+v, want resolved %v", i, transition, wantResolved)
+		}
+	}
+	if first := got[0]; first.Type != types.AlertTypeCPU || first.Severity != types.SeverityCritical ||
+		first.Value != 95 || first.Threshold != 70 || first.IncidentID != "cpu-1" {
+		t.Errorf("first transition = %+v", first)
+	}
+
+	all, err := db.Since(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 9 || !all[0].Timestamp.Equal(old.Timestamp) {
+		t.Errorf("Since(zero) returned %d transitions starting %v, want 9 starting %v", len(all), all[0].Timestamp, old.Timestamp)
+	}
+}
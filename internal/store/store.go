@@ -0,0 +1,141 @@
+// Package store keeps recent monitor samples in memory for queries by
+// metric and time.
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"stackpulse/internal/types"
+)
+
+// metrics are the values Query returns, in the units of types.Status:
+// percent, bytes, milliseconds and counts.
+var metrics = map[string]func(status *types.Status) float64{
+	"cpu":     func(s *types.Status) float64 { return s.CPU.Percent() },
+	"rss":     func(s *types.Status) float64 { return float64(s.Memory.RSS) },
+	"heap":    func(s *types.Status) float64 { return float64(s.Memory.HeapUsed) },
+	"lag":     func(s *types.Status) float64 { return s.EventLoop.Lag },
+	"elu":     func(s *types.Status) float64 { return s.EventLoop.Utilization },
+	"gc":      func(s *types.Status) float64 { return s.GC.Duration },
+	"handles": func(s *types.Status) float64 { return float64(s.Handles.Active) },
+}
+
+// Metrics returns the metric names Query accepts, sorted.
+func Metrics() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsMetric reports whether Query and Value accept metric.
+func IsMetric(metric string) bool {
+	_, ok := metrics[metric]
+	return ok
+}
+
+// Value returns the named metric of status; ok is false for an unknown name.
+func Value(metric string, status *types.Status) (value float64, ok bool) {
+	get, ok := metrics[metric]
+	if !ok {
+		return 0, false
+	}
+	return get(status), true
+}
+
+// Store is a fixed-capacity ring buffer of statuses, oldest overwritten
+// first. Samples older than the retention are left out of queries. It is
+// safe for concurrent use: one writer pushes while any number of readers
+// query.
+type Store struct {
+	capacity  int
+	retention time.Duration
+
+	mu      sync.RWMutex
+	samples []types.Status
+	start   int // index of the oldest sample once samples is full
+}
+
+// New creates a store of at most capacity samples, each kept for at most
+// retention; a retention of 0 keeps samples until they are overwritten.
+func New(capacity int, retention time.Duration) *Store {
+	return &Store{
+		capacity:  capacity,
+		retention: retention,
+		samples:   make([]types.Status, 0, capacity),
+	}
+}
+
+// Push adds a copy of status as the newest sample.
+func (s *Store) Push(status *types.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) < s.capacity {
+		s.samples = append(s.samples, *status)
+		return
+	}
+	s.samples[s.start] = *status
+	s.start = (s.start + 1) % s.capacity
+}
+
+// Latest returns a copy of the newest sample, or nil while the store is
+// empty.
+func (s *Store) Latest() *types.Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.samples) == 0 {
+		return nil
+	}
+	latest := s.samples[(s.start+len(s.samples)-1)%len(s.samples)]
+	return &latest
+}
+
+// Since returns copies of the retained samples taken at or after since,
+// oldest first.
+func (s *Store) Since(since time.Time) []types.Status {
+	var samples []types.Status
+	s.each(since, func(status *types.Status) {
+		samples = append(samples, *status)
+	})
+	return samples
+}
+
+// Query returns the named metric of the retained samples taken at or after
+// since, oldest first. An unknown metric returns nil.
+func (s *Store) Query(metric string, since time.Time) []float64 {
+	get, ok := metrics[metric]
+	if !ok {
+		return nil
+	}
+	values := []float64{}
+	s.each(since, func(status *types.Status) {
+		values = append(values, get(status))
+	})
+	return values
+}
+
+// each calls fn on the retained samples taken at or after since, oldest
+// first, holding the read lock.
+func (s *Store) each(since time.Time, fn func(status *types.Status)) {
+	if s.retention > 0 {
+		if cutoff := time.Now().Add(-s.retention); since.Before(cutoff) {
+			since = cutoff
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := range s.samples {
+		status := &s.samples[(s.start+i)%len(s.samples)]
+		if !status.Timestamp.Before(since) {
+			fn(status)
+		}
+	}
+}
@@ -11,32 +11,116 @@ type AlertType string
 type AlertSeverity string
 
 const (
-	AlertTypeCPU       AlertType = "cpu"
-	AlertTypeMemory    AlertType = "memory"
-	AlertTypeEventLoop AlertType = "eventloop"
-	AlertTypeHeap      AlertType = "heap"
-
-	SeverityInfo     AlertSeverity = "info"
-	SeverityWarning  AlertSeverity = "warning"
-	SeverityCritical AlertSeverity = "critical"
+	AlertTypeCPU         AlertType = "cpu"
+	AlertTypeMemory      AlertType = "memory"
+	AlertTypeEventLoop   AlertType = "eventloop"
+	AlertTypeHeap        AlertType = "heap"
+	AlertTypeDeopt       AlertType = "deopt"
+	AlertTypeGC          AlertType = "gc"
+	AlertTypeHandles     AlertType = "handles"
+	AlertTypeConstructor AlertType = "constructor"
+	AlertTypeGCPressure  AlertType = "gc_pressure"
+	AlertTypeStuck       AlertType = "stuck"
+	AlertTypeNetwork     AlertType = "network"
+	AlertTypeOOM         AlertType = "oom"
+	AlertTypePriority    AlertType = "priority"
+	AlertTypeTarget      AlertType = "target"
+	AlertTypeCrashLoop   AlertType = "crashloop"
+	AlertTypeDescriptors AlertType = "descriptors"
+	AlertTypeMemoryLeak  AlertType = "memory_leak"
+
+	// The event loop never ran the lag measurement: synchronous work is
+	// holding it
+	AlertTypeEventLoopBlocked AlertType = "eventloop_blocked"
+
+	// GC collections per second rose above the configured rate
+	AlertTypeGCThrashing AlertType = "gc_thrashing"
+
+	// A metric rose more than the configured number of standard deviations
+	// above its trailing mean
+	AlertTypeAnomaly AlertType = "anomaly"
+
+	SeverityInfo      AlertSeverity = "info"
+	SeverityWarning   AlertSeverity = "warning"
+	SeverityCritical  AlertSeverity = "critical"
+	SeverityEmergency AlertSeverity = "emergency"
 )
 
+// Rank orders severities from least to most severe. Unknown severities rank 0.
+func (s AlertSeverity) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	case SeverityEmergency:
+		return 4
+	}
+	return 0
+}
+
 // Alert represents a monitoring alert
 type Alert struct {
-	Type      AlertType     `json:"type"`
-	Severity  AlertSeverity `json:"severity"`
-	Message   string        `json:"message"`
-	Value     float64       `json:"value"`
-	Threshold float64       `json:"threshold"`
-	Timestamp time.Time     `json:"timestamp"`
+	Type       AlertType     `json:"type"`
+	Severity   AlertSeverity `json:"severity"`
+	Message    string        `json:"message"`
+	Value      float64       `json:"value"`
+	Threshold  float64       `json:"threshold"`
+	IncidentID string        `json:"incidentId,omitempty"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// AlertEvent records an alert firing or resolving. Alert is the alert as it
+// was last seen; Timestamp is when the transition was observed.
+type AlertEvent struct {
+	Alert     Alert     `json:"alert"`
+	Resolved  bool      `json:"resolved"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // CPUMetrics represents CPU usage metrics
+// FirstSample is set on the first sample after attaching to a process:
+// usage is measured between samples, so it is 0 and should be ignored.
+// Usage is raw, 100 per busy core, so it can exceed 100 on multi-core
+// machines. NormalizedUsage is Usage divided by Cores, the number of logical
+// CPUs, so 100 means all of them busy. Normalized is set when thresholds and
+// the dashboard use NormalizedUsage (--cpu-normalize machine).
 type CPUMetrics struct {
-	Usage      float64   `json:"usage"`
-	UserTime   float64   `json:"userTime"`
-	SystemTime float64   `json:"systemTime"`
-	Timestamp  time.Time `json:"timestamp"`
+	Usage           float64   `json:"usage"`
+	NormalizedUsage float64   `json:"normalizedUsage"`
+	SmoothedUsage   float64   `json:"smoothedUsage,omitempty"`
+	Cores           int       `json:"cores,omitempty"`
+	Normalized      bool      `json:"normalized,omitempty"`
+	Smoothed        bool      `json:"smoothed,omitempty"`
+	UserTime        float64   `json:"userTime"`
+	SystemTime      float64   `json:"systemTime"`
+	FirstSample     bool      `json:"firstSample,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Percent returns CPU usage on the scale thresholds are set in, taken from
+// the moving average SmoothedUsage when Smoothed is set and the latest poll
+// otherwise.
+func (c CPUMetrics) Percent() float64 {
+	if !c.Smoothed {
+		return c.InstantPercent()
+	}
+	if c.Normalized && c.Cores > 0 {
+		return c.SmoothedUsage / float64(c.Cores)
+	}
+	return c.SmoothedUsage
+}
+
+// InstantPercent returns the CPU usage of the latest poll on the scale
+// thresholds are set in: NormalizedUsage when Normalized is set, Usage
+// otherwise.
+func (c CPUMetrics) InstantPercent() float64 {
+	if c.Normalized {
+		return c.NormalizedUsage
+	}
+	return c.Usage
 }
 
 // MemoryMetrics represents memory usage metrics
@@ -47,6 +131,21 @@ type MemoryMetrics struct {
 	HeapUsed   uint64    `json:"heapUsed"`
 	External   uint64    `json:"external"`
 	Timestamp  time.Time `json:"timestamp"`
+
+	// Swapped-out and shared resident memory in bytes. Extended is false
+	// and both are zero where the platform does not report them
+	Swap     uint64 `json:"swap"`
+	Shared   uint64 `json:"shared"`
+	Extended bool   `json:"extended"`
+}
+
+// HeapUsagePercent returns heap used as a percentage of heap total. ok is
+// false when the heap total is unknown (zero), so callers never divide by it.
+func HeapUsagePercent(m MemoryMetrics) (float64, bool) {
+	if m.HeapTotal == 0 {
+		return 0, false
+	}
+	return float64(m.HeapUsed) / float64(m.HeapTotal) * 100, true
 }
 
 // EventLoopMetrics represents event loop performance metrics
@@ -58,28 +157,38 @@ type EventLoopMetrics struct {
 	Min         float64   `json:"min"`
 	Utilization float64   `json:"utilization"`
 	Timestamp   time.Time `json:"timestamp"`
+
+	// Consecutive polls whose lag measurement timed out because the loop
+	// never ran it, and how long it has been blocked since the first of
+	// them in milliseconds; Lag reports the same duration meanwhile
+	Timeouts  int     `json:"timeouts,omitempty"`
+	BlockedMs float64 `json:"blockedMs,omitempty"`
 }
 
-// ThreadPoolMetrics represents thread pool metrics
+// ThreadPoolMetrics represents thread pool metrics. Estimated is set when
+// the counts are derived from the requests seen in flight rather than read
+// from libuv, or could not be read at all.
 type ThreadPoolMetrics struct {
 	QueueSize    int       `json:"queueSize"`
 	PoolSize     int       `json:"poolSize"`
 	ActiveCount  int       `json:"activeCount"`
 	PendingCount int       `json:"pendingCount"`
+	Estimated    bool      `json:"estimated"`
 	Timestamp    time.Time `json:"timestamp"`
 }
 
 // GCMetrics represents garbage collection metrics
 type GCMetrics struct {
-	Collections      int       `json:"collections"`
-	Duration         float64   `json:"duration"`
-	HeapSizeBefore   uint64    `json:"heapSizeBefore"`
-	HeapSizeAfter    uint64    `json:"heapSizeAfter"`
-	Type             string    `json:"type"`
-	Reason           string    `json:"reason"`
-	CollectionsTotal int       `json:"collectionsTotal"`
-	DurationTotal    float64   `json:"durationTotal"`
-	Timestamp        time.Time `json:"timestamp"`
+	Collections       int       `json:"collections"`
+	Duration          float64   `json:"duration"`
+	HeapSizeBefore    uint64    `json:"heapSizeBefore"`
+	HeapSizeAfter     uint64    `json:"heapSizeAfter"`
+	Type              string    `json:"type"`
+	Reason            string    `json:"reason"`
+	CollectionsTotal  int       `json:"collectionsTotal"`
+	DurationTotal     float64   `json:"durationTotal"`
+	ReclaimEfficiency float64   `json:"reclaimEfficiency"`
+	Timestamp         time.Time `json:"timestamp"`
 }
 
 // HandleMetrics represents handle usage metrics
@@ -90,9 +199,45 @@ type HandleMetrics struct {
 	TCPSockets int      `json:"tcpSockets"`
 	UDPSockets int      `json:"udpSockets"`
 	Files     int       `json:"files"`
+
+	// Open file descriptors and the soft and hard RLIMIT_NOFILE of the
+	// process; zero where the platform does not report them
+	FDs         int    `json:"fds"`
+	FDLimit     uint64 `json:"fdLimit"`
+	FDHardLimit uint64 `json:"fdHardLimit"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FDUsagePercent returns open descriptors as a percentage of the soft
+// limit. ok is false when the limit is unknown.
+func FDUsagePercent(handles HandleMetrics) (percent float64, ok bool) {
+	if handles.FDLimit == 0 {
+		return 0, false
+	}
+	return float64(handles.FDs) / float64(handles.FDLimit) * 100, true
+}
+
+// NetworkMetrics represents network traffic visible to the process.
+// Rates are in bytes per second.
+type NetworkMetrics struct {
+	BytesRecv uint64    `json:"bytesRecv"`
+	BytesSent uint64    `json:"bytesSent"`
+	RxRate    float64   `json:"rxRate"`
+	TxRate    float64   `json:"txRate"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// SchedulingMetrics represents the process's scheduling priority and the
+// kernel's OOM-kill score (0-1000, higher is killed first). OOM fields are
+// -1 on platforms without OOM scores.
+type SchedulingMetrics struct {
+	Nice        int32     `json:"nice"`
+	OOMScore    int       `json:"oomScore"`
+	OOMScoreAdj int       `json:"oomScoreAdj"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 // V8Metrics represents V8 engine specific metrics
 type V8Metrics struct {
 	HeapSpaceUsed      map[string]uint64 `json:"heapSpaceUsed"`
@@ -100,19 +245,115 @@ type V8Metrics struct {
 	HeapSpaceAvailable map[string]uint64 `json:"heapSpaceAvailable"`
 	MallocedMemory     uint64            `json:"mallocedMemory"`
 	PeakMallocedMemory uint64            `json:"peakMallocedMemory"`
+	HeapSizeLimit      uint64            `json:"heapSizeLimit,omitempty"`
+	// Deoptimized frames (CPU profile call sites with a deopt reason) in
+	// the last profiler window, and per second of it
+	DeoptCount         int               `json:"deoptCount"`
+	DeoptRate          float64           `json:"deoptRate"`
+	TopDeoptReason     string            `json:"topDeoptReason"`
+	CodeSize           uint64            `json:"codeSize"`
+	BytecodeSize       uint64            `json:"bytecodeSize"`
 	Timestamp          time.Time         `json:"timestamp"`
 }
 
+// ConstructorMetrics represents heap usage of one tracked constructor
+type ConstructorMetrics struct {
+	Name          string    `json:"name"`
+	Count         int       `json:"count"`
+	SelfSize      uint64    `json:"selfSize"`
+	RetainedSize  uint64    `json:"retainedSize"`
+	GrowthPercent float64   `json:"growthPercent"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// ConstructorDiff compares one constructor between two heap snapshots
+type ConstructorDiff struct {
+	Name           string `json:"name"`
+	CountBefore    int    `json:"countBefore"`
+	CountAfter     int    `json:"countAfter"`
+	RetainedBefore uint64 `json:"retainedBefore"`
+	RetainedAfter  uint64 `json:"retainedAfter"`
+}
+
+func (d ConstructorDiff) CountDelta() int {
+	return d.CountAfter - d.CountBefore
+}
+
+func (d ConstructorDiff) RetainedDelta() int64 {
+	return int64(d.RetainedAfter) - int64(d.RetainedBefore)
+}
+
+// SourceAvailability records which metric groups were read from the
+// target's V8 inspector on their latest sample. A false group holds
+// fallback values: zeros or placeholders.
+type SourceAvailability struct {
+	HeapUsage  bool `json:"heapUsage"`
+	EventLoop  bool `json:"eventLoop"`
+	ThreadPool bool `json:"threadPool"`
+	GC         bool `json:"gc"`
+	Handles    bool `json:"handles"`
+	V8         bool `json:"v8"`
+}
+
+// Inspector reports whether any group was read through the inspector.
+func (a SourceAvailability) Inspector() bool {
+	return a.HeapUsage || a.EventLoop || a.ThreadPool || a.GC || a.Handles || a.V8
+}
+
 // Status represents the current monitoring status
 type Status struct {
-	PID         int               `json:"pid"`
-	CPU         CPUMetrics        `json:"cpu"`
-	Memory      MemoryMetrics     `json:"memory"`
-	EventLoop   EventLoopMetrics  `json:"eventLoop"`
-	ThreadPool  ThreadPoolMetrics `json:"threadPool"`
-	GC          GCMetrics         `json:"gc"`
-	Handles     HandleMetrics     `json:"handles"`
-	V8          V8Metrics         `json:"v8"`
-	Timestamp   time.Time         `json:"timestamp"`
-	Alerts      []Alert           `json:"alerts"`
-}
\ No newline at end of file
+	PID          int                  `json:"pid"`
+	StartedAt    time.Time            `json:"startedAt"`
+	CPU          CPUMetrics           `json:"cpu"`
+	Memory       MemoryMetrics        `json:"memory"`
+	EventLoop    EventLoopMetrics     `json:"eventLoop"`
+	ThreadPool   ThreadPoolMetrics    `json:"threadPool"`
+	GC           GCMetrics            `json:"gc"`
+	Handles      HandleMetrics        `json:"handles"`
+	V8           V8Metrics            `json:"v8"`
+	Network      *NetworkMetrics      `json:"network,omitempty"`
+	Scheduling   *SchedulingMetrics   `json:"scheduling,omitempty"`
+	Constructors []ConstructorMetrics `json:"constructors,omitempty"`
+	Custom       map[string]float64   `json:"custom,omitempty"`
+	Annotations  []Annotation         `json:"annotations,omitempty"`
+	AlertEvents  []AlertEvent         `json:"alertEvents,omitempty"`
+	Timestamp    time.Time            `json:"timestamp"`
+	Alerts       []Alert              `json:"alerts"`
+
+	// InspectorAvailable is false when none of the Node-specific groups
+	// could be read from the inspector, so their values are estimates;
+	// Sources breaks it down by group
+	InspectorAvailable bool               `json:"inspectorAvailable"`
+	Sources            SourceAvailability `json:"sources"`
+
+	// Uptime is the seconds the process had been running at Timestamp,
+	// 0 when StartedAt is unknown
+	Uptime float64 `json:"uptime"`
+}
+
+// Annotation marks an external event such as a deploy. The Before fields
+// hold the last sample taken before it, for before/after comparison.
+type Annotation struct {
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+	CPUBefore float64   `json:"cpuBefore"`
+	RSSBefore uint64    `json:"rssBefore"`
+	LagBefore float64   `json:"lagBefore"`
+}
+// SessionSummary aggregates a monitoring session for the report printed
+// when it ends. Alerts counts the incidents opened by type and severity;
+// GC totals are those of the collector since monitoring started.
+type SessionSummary struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Samples  int           `json:"samples"`
+
+	Alerts map[AlertType]map[AlertSeverity]int `json:"alerts"`
+
+	PeakCPU float64 `json:"peakCpu"`
+	PeakRSS uint64  `json:"peakRss"`
+	PeakLag float64 `json:"peakLag"`
+
+	GCCollections int     `json:"gcCollections"`
+	GCDuration    float64 `json:"gcDuration"`
+}
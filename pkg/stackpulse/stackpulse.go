@@ -0,0 +1,63 @@
+// Package stackpulse exposes StackPulse's metric collection and alerting for
+// embedding in other Go programs. Nothing in this package renders to the
+// terminal, writes to stdout, or exits the process; warnings are reported
+// through the standard log package.
+//
+//	mon, err := stackpulse.New(&stackpulse.Config{
+//		PID:             pid,
+//		InspectPort:     9229,
+//		PollingInterval: time.Second,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	// One collection cycle: metrics and alerts, with no dashboard,
+//	// notifiers or polling ticker
+//	status, err := mon.Collect(ctx)
+//
+//	// Or continuous monitoring, which also sends alerts to the configured
+//	// notifiers
+//	updates := make(chan stackpulse.Status, 16)
+//	mon.Subscribe(updates)
+//	go mon.Start(ctx)
+package stackpulse
+
+import (
+	"stackpulse/internal/alerts"
+	"stackpulse/internal/config"
+	"stackpulse/internal/metrics"
+	"stackpulse/internal/monitor"
+	"stackpulse/internal/types"
+)
+
+type (
+	Config       = config.ServiceConfig
+	Status       = types.Status
+	Alert        = types.Alert
+	Monitor      = monitor.Monitor
+	Collector    = metrics.Collector
+	AlertManager = alerts.Manager
+)
+
+// New validates cfg and returns a headless monitor. Settings left at zero
+// that Validate would reject take the defaults of the watch flags (see
+// config.ServiceConfig.SetDefaults).
+func New(cfg *Config) (*Monitor, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return monitor.NewHeadless(cfg), nil
+}
+
+// NewCollector returns a collector for reading individual metric groups.
+func NewCollector(cfg *Config) *Collector {
+	return metrics.NewCollector(cfg)
+}
+
+// NewAlertManager returns an alert manager for evaluating thresholds
+// against statuses gathered elsewhere.
+func NewAlertManager() *AlertManager {
+	return alerts.NewManager()
+}
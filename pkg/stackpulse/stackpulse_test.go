@@ -0,0 +1,52 @@
+package stackpulse
+
+import (
+	"testing"
+
+	"stackpulse/internal/config"
+)
+
+// TestNewDefaults checks that a config naming only the process passes
+// validation, with the defaults of the watch flags filled in.
+func TestNewDefaults(t *testing.T) {
+	cfg := &Config{PID: 1234, InspectPort: 9229}
+	if _, err := New(cfg); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if cfg.GCReclaimCount != config.DefaultGCReclaimCount {
+		t.Errorf("GCReclaimCount = %d, want %d", cfg.GCReclaimCount, config.DefaultGCReclaimCount)
+	}
+	if cfg.PollingInterval != config.DefaultPollingInterval {
+		t.Errorf("PollingInterval = %v, want %v", cfg.PollingInterval, config.DefaultPollingInterval)
+	}
+	if cfg.CPUThreshold != config.DefaultCPUThreshold {
+		t.Errorf("CPUThreshold = %v, want %v", cfg.CPUThreshold, config.DefaultCPUThreshold)
+	}
+}
+
+// TestNewFeatureDefaults checks that enabling a feature without its
+// settings fills them in rather than failing validation.
+func TestNewFeatureDefaults(t *testing.T) {
+	cfg := &Config{
+		PID:               1234,
+		InspectPort:       9229,
+		CompareRuntime:    true,
+		ExitOnRecovery:    true,
+		CaptureOnCritical: true,
+		Anomaly:           []string{"cpu"},
+	}
+	if _, err := New(cfg); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+}
+
+// TestNewKeepsSettings checks that settings given are not overridden.
+func TestNewKeepsSettings(t *testing.T) {
+	cfg := &Config{PID: 1234, InspectPort: 9229, GCReclaimCount: 5, CPUThreshold: 90}
+	if _, err := New(cfg); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if cfg.GCReclaimCount != 5 || cfg.CPUThreshold != 90 {
+		t.Errorf("New changed the settings given: GCReclaimCount %d, CPUThreshold %v", cfg.GCReclaimCount, cfg.CPUThreshold)
+	}
+}